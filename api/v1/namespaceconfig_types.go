@@ -17,7 +17,10 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -28,19 +31,587 @@ type NamespaceConfigSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Foo is an example field of NamespaceConfig. Edit namespaceconfig_types.go to remove/update
+	// Labels enforced on the namespace. Other labels are removed, except the
+	// kubernetes.io ones and the ones an adopted namespace had before.
 	Labels          map[string]string `json:"labels,omitempty"`
 	NamespacePrefix string            `json:"namespacePrefix,omitempty"`
+	// NameTemplate is a Go text/template rendering the namespace name. It
+	// replaces namespacePrefix + name and can use .Name, .Namespace, .Prefix,
+	// .Labels and .Spec of the CRD and the environment name as .Env,
+	// e.g. {{.Prefix}}{{.Name}}-{{.Env}}
+	// +optional
+	NameTemplate string `json:"nameTemplate,omitempty"`
+	// TruncateName shortens generated names longer than 63 characters,
+	// replacing their end with a stable hash. Long names are rejected otherwise.
+	// +optional
+	TruncateName bool `json:"truncateName,omitempty"`
+	// NameChangePolicy decides what happens to a managed namespace when an
+	// edit of the spec changes its generated name. Defaults to Block.
+	// +kubebuilder:default=Block
+	// +optional
+	NameChangePolicy NameChangePolicy `json:"nameChangePolicy,omitempty"`
+	// AdoptionPolicy decides whether an existing namespace without the
+	// ric.com/owner annotation may be taken over. Defaults to Never.
+	// +kubebuilder:default=Never
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// DeletionPolicy decides what happens to the managed namespaces when the
+	// CRD or one of its environments is removed. Defaults to Delete.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Annotations merged onto the namespace. Annotations set by other tools
+	// are kept.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ResourceQuota is enforced as a ResourceQuota inside the managed namespace.
+	// Removing it from the spec deletes the quota.
+	// +optional
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+
+	// LimitRange is enforced as a LimitRange inside the managed namespace.
+	// Removing it from the spec deletes the LimitRange.
+	// +optional
+	LimitRange *LimitRangeSpec `json:"limitRange,omitempty"`
+
+	// NetworkPolicy selects the baseline network isolation of the namespace
+	// +optional
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// Access grants ClusterRoles inside the namespace. Each entry becomes a
+	// RoleBinding and entries removed from the list are unbound.
+	// +listType=map
+	// +listMapKey=clusterRole
+	// +optional
+	Access []AccessBinding `json:"access,omitempty"`
+
+	// ServiceAccounts provisioned in the namespace
+	// +optional
+	ServiceAccounts *ServiceAccountsSpec `json:"serviceAccounts,omitempty"`
+
+	// Secrets copied from other namespaces into the namespace. Copies follow
+	// their source and are deleted once their reference is removed. Sources
+	// outside the source namespaces of the operator must carry the
+	// ric.com/replicable=true annotation.
+	// +optional
+	Secrets []SourceReference `json:"secrets,omitempty"`
+
+	// ConfigMaps copied from other namespaces into the namespace. Copies
+	// follow their source and are deleted once their reference is removed.
+	// Sources outside the source namespaces of the operator must carry the
+	// ric.com/replicable=true annotation.
+	// +optional
+	ConfigMaps []ConfigMapReference `json:"configMaps,omitempty"`
+
+	// PodSecurity sets the Pod Security Admission labels of the namespace. It
+	// takes precedence over pod-security.kubernetes.io keys in Labels.
+	// +optional
+	PodSecurity *PodSecuritySpec `json:"podSecurity,omitempty"`
+
+	// Scheduling constrains where the pods of the namespace run. It requires
+	// the PodNodeSelector and PodTolerationRestriction admission plugins.
+	// +optional
+	Scheduling *SchedulingSpec `json:"scheduling,omitempty"`
+
+	// Bootstrap references a ConfigMap holding multi-document YAML applied
+	// into the namespace. Objects removed from it are pruned. It follows the
+	// same source rules as ConfigMaps.
+	// +optional
+	Bootstrap *BootstrapReference `json:"bootstrap,omitempty"`
+
+	// Resources are raw objects created inside the namespace. Objects removed
+	// from the list are deleted. The operator may manage ConfigMaps, Secrets,
+	// Services, ServiceAccounts, Roles, RoleBindings, ResourceQuotas,
+	// LimitRanges and NetworkPolicies. Other kinds need extra RBAC granted to
+	// the operator and are reported as UnsupportedResource until then.
+	// +optional
+	Resources []runtime.RawExtension `json:"resources,omitempty"`
+
+	// Owner is rendered into well-known labels and annotations of the
+	// namespace for chargeback
+	// +optional
+	Owner *OwnerSpec `json:"owner,omitempty"`
+
+	// Environments fans the CRD out to one namespace per entry. Each entry
+	// inherits the spec and applies its own overrides. Namespaces of entries
+	// removed from the list are deleted.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Environments []Environment `json:"environments,omitempty"`
+}
+
+// LimitRangeSpec defines the defaults and bounds applied to workloads in the namespace
+type LimitRangeSpec struct {
+	// Container holds the defaults and bounds applied to each container
+	// +optional
+	Container *ContainerLimits `json:"container,omitempty"`
+	// Pod holds the bounds applied to the sum of all containers of a pod
+	// +optional
+	Pod *PodLimits `json:"pod,omitempty"`
+	// PersistentVolumeClaim holds the storage bounds applied to each claim
+	// +optional
+	PersistentVolumeClaim *StorageLimits `json:"persistentVolumeClaim,omitempty"`
+}
+
+// ContainerLimits defines the defaults and bounds applied to each container
+type ContainerLimits struct {
+	// Default limits set on containers that do not declare them
+	// +optional
+	Default corev1.ResourceList `json:"default,omitempty"`
+	// DefaultRequest requests set on containers that do not declare them
+	// +optional
+	DefaultRequest corev1.ResourceList `json:"defaultRequest,omitempty"`
+	// +optional
+	Min corev1.ResourceList `json:"min,omitempty"`
+	// +optional
+	Max corev1.ResourceList `json:"max,omitempty"`
+}
+
+// PodLimits defines the bounds applied to the sum of all containers of a pod
+type PodLimits struct {
+	// +optional
+	Min corev1.ResourceList `json:"min,omitempty"`
+	// +optional
+	Max corev1.ResourceList `json:"max,omitempty"`
+}
+
+// StorageLimits defines the storage bounds applied to each PersistentVolumeClaim
+type StorageLimits struct {
+	// +optional
+	Min *resource.Quantity `json:"min,omitempty"`
+	// +optional
+	Max *resource.Quantity `json:"max,omitempty"`
+}
+
+// NetworkProfile names a baseline set of NetworkPolicies
+// +kubebuilder:validation:Enum=none;default-deny;same-namespace-only
+type NetworkProfile string
+
+const (
+	// NetworkProfileNone leaves the namespace fully open
+	NetworkProfileNone NetworkProfile = "none"
+	// NetworkProfileDefaultDeny denies all ingress and egress traffic
+	NetworkProfileDefaultDeny NetworkProfile = "default-deny"
+	// NetworkProfileSameNamespaceOnly only allows traffic between pods of the
+	// namespace, plus DNS lookups
+	NetworkProfileSameNamespaceOnly NetworkProfile = "same-namespace-only"
+)
+
+// NetworkPolicySpec defines the network isolation applied to the namespace
+type NetworkPolicySpec struct {
+	// Profile is the baseline isolation. Defaults to none.
+	// +kubebuilder:default=none
+	// +optional
+	Profile NetworkProfile `json:"profile,omitempty"`
+	// AllowedNamespaces selects extra namespaces allowed to talk to the
+	// namespace in both directions. Ignored with the none profile.
+	// +optional
+	AllowedNamespaces []metav1.LabelSelector `json:"allowedNamespaces,omitempty"`
+	// AllowedEgressCIDRs lists extra CIDRs pods are allowed to reach.
+	// Ignored with the none profile.
+	// +optional
+	AllowedEgressCIDRs []string `json:"allowedEgressCIDRs,omitempty"`
+}
+
+// AccessBinding grants a ClusterRole inside the namespace to a set of subjects
+type AccessBinding struct {
+	// ClusterRole bound in the namespace, such as admin, edit or view
+	// +kubebuilder:validation:MinLength=1
+	ClusterRole string `json:"clusterRole"`
+	// +optional
+	Users []string `json:"users,omitempty"`
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+	// +optional
+	ServiceAccounts []ServiceAccountSubject `json:"serviceAccounts,omitempty"`
+}
+
+// ServiceAccountSubject references a ServiceAccount granted access
+type ServiceAccountSubject struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the ServiceAccount. Defaults to the managed namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ServiceAccountsSpec defines the ServiceAccounts provisioned in the namespace
+type ServiceAccountsSpec struct {
+	// Items lists the ServiceAccounts kept in sync. An item named default
+	// patches the ServiceAccount Kubernetes creates in every namespace.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Items []ServiceAccountSpec `json:"items,omitempty"`
+	// HardenDefault disables token automounting on the default ServiceAccount
+	// +optional
+	HardenDefault bool `json:"hardenDefault,omitempty"`
+}
+
+// ServiceAccountSpec defines a ServiceAccount provisioned in the namespace
+type ServiceAccountSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Annotations merged onto the ServiceAccount
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ImagePullSecrets names Secrets in the namespace used to pull images
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+	// AutomountServiceAccountToken sets whether pods mount the token by default
+	// +optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+}
+
+// SourceReference points to an object copied into the namespace
+type SourceReference struct {
+	// Name of the source object
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the source object
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// TargetName of the copy. Defaults to the source name.
+	// +optional
+	TargetName string `json:"targetName,omitempty"`
+}
+
+// ConfigMapReference points to a ConfigMap copied into the namespace
+type ConfigMapReference struct {
+	SourceReference `json:",inline"`
+	// Keys selects the keys copied and optionally renames them. Every key is
+	// copied when empty.
+	// +optional
+	Keys []KeyMapping `json:"keys,omitempty"`
+}
+
+// KeyMapping selects a key of the source and the key it gets in the copy
+type KeyMapping struct {
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+	// TargetKey in the copy. Defaults to Key.
+	// +optional
+	TargetKey string `json:"targetKey,omitempty"`
 }
 
+// PodSecurityLevel is a Pod Security Standard
+// +kubebuilder:validation:Enum=privileged;baseline;restricted
+type PodSecurityLevel string
+
+const (
+	PodSecurityLevelPrivileged PodSecurityLevel = "privileged"
+	PodSecurityLevelBaseline   PodSecurityLevel = "baseline"
+	PodSecurityLevelRestricted PodSecurityLevel = "restricted"
+)
+
+// PodSecuritySpec defines the Pod Security Admission modes of the namespace.
+// Versions are either latest or a Kubernetes minor version such as v1.27.
+type PodSecuritySpec struct {
+	// Enforce rejects pods violating the level
+	// +optional
+	Enforce PodSecurityLevel `json:"enforce,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	// +optional
+	EnforceVersion string `json:"enforceVersion,omitempty"`
+	// Audit records violations of the level in the audit log
+	// +optional
+	Audit PodSecurityLevel `json:"audit,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	// +optional
+	AuditVersion string `json:"auditVersion,omitempty"`
+	// Warn returns a warning to users creating pods violating the level
+	// +optional
+	Warn PodSecurityLevel `json:"warn,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	// +optional
+	WarnVersion string `json:"warnVersion,omitempty"`
+}
+
+// SchedulingSpec defines the namespace-level scheduling constraints
+type SchedulingSpec struct {
+	// NodeSelector merged into the node selector of every pod of the namespace
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// DefaultTolerations added to every pod of the namespace
+	// +optional
+	DefaultTolerations []corev1.Toleration `json:"defaultTolerations,omitempty"`
+}
+
+// BootstrapReference points to a ConfigMap holding the manifests applied into
+// the namespace
+type BootstrapReference struct {
+	// Name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// Key holding the manifests. Every key is applied when empty.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// AppliedObject identifies an object the operator applied into the namespace
+type AppliedObject struct {
+	// +optional
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+}
+
+// NameChangePolicy decides what happens to a namespace whose generated name changed
+// +kubebuilder:validation:Enum=Block;Keep;Migrate
+type NameChangePolicy string
+
+const (
+	// NameChangeBlock refuses the change and writes nothing until it is reverted
+	NameChangeBlock NameChangePolicy = "Block"
+	// NameChangeKeep creates the new namespace and releases the old one,
+	// which is kept without the ownership annotation
+	NameChangeKeep NameChangePolicy = "Keep"
+	// NameChangeMigrate creates the new namespace and deletes the old one once
+	// it holds no pods nor persistent volume claims
+	NameChangeMigrate NameChangePolicy = "Migrate"
+)
+
+// AdoptionPolicy decides whether an existing namespace is taken over
+// +kubebuilder:validation:Enum=Never;IfUnowned;Force
+type AdoptionPolicy string
+
+const (
+	// AdoptionNever reports a conflict on any namespace the operator did not create
+	AdoptionNever AdoptionPolicy = "Never"
+	// AdoptionIfUnowned takes over namespaces without the ownership annotation
+	// that no other CRD claims
+	AdoptionIfUnowned AdoptionPolicy = "IfUnowned"
+	// AdoptionForce takes over the namespace whoever owns it, including
+	// another CRD
+	AdoptionForce AdoptionPolicy = "Force"
+)
+
+// DeletionPolicy decides what happens to a namespace no longer managed
+// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionDelete deletes the namespace and everything inside it
+	DeletionDelete DeletionPolicy = "Delete"
+	// DeletionRetain keeps the namespace and strips the ownership annotations,
+	// so the operator no longer manages it
+	DeletionRetain DeletionPolicy = "Retain"
+	// DeletionOrphan keeps the namespace as it is
+	DeletionOrphan DeletionPolicy = "Orphan"
+)
+
+// NamespaceConfigPhase is the lifecycle phase of a CRD
+// +kubebuilder:validation:Enum=Active;Terminating
+type NamespaceConfigPhase string
+
+const (
+	// PhaseActive means the CRD manages its namespaces
+	PhaseActive NamespaceConfigPhase = "Active"
+	// PhaseTerminating means the CRD is deleted and waits for its namespaces
+	// to be gone before releasing its finalizer
+	PhaseTerminating NamespaceConfigPhase = "Terminating"
+)
+
+// TerminationDiagnostic reports what blocks the deletion of a namespace
+type TerminationDiagnostic struct {
+	Namespace string `json:"namespace"`
+	// Conditions are the true status conditions of the namespace, such as
+	// NamespaceFinalizersRemaining, with their message
+	// +optional
+	Conditions []string `json:"conditions,omitempty"`
+	// Remaining lists the kinds of objects still in the namespace
+	// +optional
+	Remaining []RemainingResource `json:"remaining,omitempty"`
+}
+
+// RemainingResource counts the objects of a kind left in a terminating namespace
+type RemainingResource struct {
+	// Resource is the resource name qualified by its group, e.g. pods or
+	// rolebindings.rbac.authorization.k8s.io
+	Resource string `json:"resource"`
+	Count    int    `json:"count"`
+	// Finalizers set on the remaining objects
+	// +optional
+	Finalizers []string `json:"finalizers,omitempty"`
+}
+
+// AdoptedNamespace records an existing namespace taken over by the CRD
+type AdoptedNamespace struct {
+	Name string `json:"name"`
+	// PreviousOwner is the namespace/name of the CRD that owned the namespace.
+	// Empty when the namespace was unmanaged.
+	// +optional
+	PreviousOwner string `json:"previousOwner,omitempty"`
+	// AdoptedAt is when the namespace was taken over
+	AdoptedAt metav1.Time `json:"adoptedAt"`
+}
+
+// Environment defines a namespace derived from the CRD, such as dev, stg or prod
+type Environment struct {
+	// Name of the environment. Available to name templates as .Env.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Prefix replaces spec.namespacePrefix for this environment
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix appended to the generated namespace name
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+	// Labels merged over spec.labels
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations merged over spec.annotations
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ResourceQuota replaces spec.resourceQuota
+	// +optional
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+}
+
+// EnvironmentStatus reports the state of the namespace of an environment
+type EnvironmentStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Ready tells whether the namespace matches the spec
+	Ready bool `json:"ready"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// OwnerSpec defines who owns the namespace and who pays for it
+type OwnerSpec struct {
+	// Name of the person or system owning the namespace
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	Name string `json:"name"`
+	// Team owning the namespace
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	Team string `json:"team"`
+	// Contact email of the owner
+	// +kubebuilder:validation:Format=email
+	// +optional
+	Contact string `json:"contact,omitempty"`
+	// CostCenter charged for the namespace. The operator may restrict it to a
+	// pattern with --cost-center-pattern.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	// +optional
+	CostCenter string `json:"costCenter,omitempty"`
+}
+
+const (
+	// ConditionReady tells whether the namespace matches the spec
+	ConditionReady string = "Ready"
+	// ConditionNameTruncated is set when the generated name was truncated
+	ConditionNameTruncated string = "NameTruncated"
+	// ConditionConflict is set when another owner claims a generated namespace
+	ConditionConflict string = "Conflict"
+
+	// ReasonReconciled means every object of the spec was enforced
+	ReasonReconciled string = "Reconciled"
+	// ReasonInvalidSpec means the spec was rejected and nothing was written
+	ReasonInvalidSpec string = "InvalidSpec"
+	// ReasonReconcileFailed means writing the namespace or its contents failed
+	ReasonReconcileFailed string = "ReconcileFailed"
+	// ReasonNameChangeBlocked means the spec changes the namespace name and
+	// the Block policy refused it
+	ReasonNameChangeBlocked string = "NameChangeBlocked"
+	// ReasonInvalidName means the namespace name could not be generated
+	ReasonInvalidName string = "InvalidNamespaceName"
+	// ReasonTooLong means the generated name exceeded 63 characters
+	ReasonTooLong string = "NameTooLong"
+	// ReasonNamespaceConflict means a generated namespace belongs to another
+	// owner and nothing was written
+	ReasonNamespaceConflict string = "NamespaceConflict"
+	// ReasonProtectedNamespace means a generated namespace matches the
+	// protected namespaces of the operator and nothing was written
+	ReasonProtectedNamespace string = "ProtectedNamespace"
+	// ReasonAdopted means an existing namespace was taken over
+	ReasonAdopted string = "Adopted"
+	// ReasonNamespaceDeleted means a namespace was deleted with its CRD
+	ReasonNamespaceDeleted string = "NamespaceDeleted"
+	// ReasonNamespaceRetained means a namespace was kept and released
+	ReasonNamespaceRetained string = "NamespaceRetained"
+	// ReasonNamespaceOrphaned means a namespace was kept as it is
+	ReasonNamespaceOrphaned string = "NamespaceOrphaned"
+	// ReasonNamespaceStuck means a deleted namespace is still terminating
+	ReasonNamespaceStuck string = "NamespaceStuck"
+	// ReasonFinalizersStripped means finalizers of known-safe kinds were
+	// removed to unblock a terminating namespace
+	ReasonFinalizersStripped string = "FinalizersStripped"
+	// ReasonSourceNotAllowed means the spec references a Secret or ConfigMap
+	// the operator may not copy
+	ReasonSourceNotAllowed string = "SourceNotAllowed"
+	// ReasonUnsupportedResource means a raw or bootstrap object is of a kind
+	// the cluster does not serve or the operator may not manage
+	ReasonUnsupportedResource string = "UnsupportedResource"
+)
+
 // NamespaceConfigStatus defines the observed state of NamespaceConfig
 type NamespaceConfigStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Phase is Active while the CRD manages its namespaces and Terminating
+	// while it waits for them to be deleted
+	// +optional
+	Phase NamespaceConfigPhase `json:"phase,omitempty"`
+	// TerminationStartedAt is when the CRD started waiting for its namespaces
+	// to be deleted
+	// +optional
+	TerminationStartedAt *metav1.Time `json:"terminationStartedAt,omitempty"`
+	// TerminationElapsed is how long the CRD has been waiting, e.g. 2m30s
+	// +optional
+	TerminationElapsed string `json:"terminationElapsed,omitempty"`
+	// TerminatingNamespaces lists the namespaces still being deleted
+	// +optional
+	TerminatingNamespaces []string `json:"terminatingNamespaces,omitempty"`
+	// TerminationDiagnostics explains what keeps the namespaces terminating
+	// once they are stuck
+	// +optional
+	TerminationDiagnostics []TerminationDiagnostic `json:"terminationDiagnostics,omitempty"`
+	// TerminationDiagnosedAt is when the terminating namespaces were last
+	// diagnosed
+	// +optional
+	TerminationDiagnosedAt *metav1.Time `json:"terminationDiagnosedAt,omitempty"`
+	// BootstrapObjects lists the objects applied from the bootstrap ConfigMap
+	// +optional
+	BootstrapObjects []AppliedObject `json:"bootstrapObjects,omitempty"`
+	// Resources lists the objects applied from spec.resources
+	// +optional
+	Resources []AppliedObject `json:"resources,omitempty"`
+	// Namespace is the namespace currently managed. Empty with environments.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// RetiredNamespaces are namespaces left behind by a name change with the
+	// Migrate policy. They are deleted once empty.
+	// +optional
+	RetiredNamespaces []string `json:"retiredNamespaces,omitempty"`
+	// Environments reports the namespace of every environment
+	// +optional
+	Environments []EnvironmentStatus `json:"environments,omitempty"`
+	// AdoptedNamespaces lists the existing namespaces taken over by the CRD
+	// +optional
+	AdoptedNamespaces []AdoptedNamespace `json:"adoptedNamespaces,omitempty"`
+	// Conditions describe the latest observations of the CRD
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // NamespaceConfig is the Schema for the namespaceconfigs API
 type NamespaceConfig struct {
@@ -19,6 +19,8 @@ package main
 import (
 	"flag"
 	"os"
+	"regexp"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -26,6 +28,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -33,6 +37,7 @@ import (
 
 	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
 	"github.com/RicHincapie/ns-operator/internal/controller"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +57,35 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var costCenterPattern string
+	var protectedNamespaces string
+	var protectedNamespacesFile string
+	var stripSafeFinalizers bool
+	var operatorUsername string
+	var namespaceDeletionGroups string
+	var sourceNamespaces string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&costCenterPattern, "cost-center-pattern", "",
+		"Regular expression every spec.owner.costCenter must match. Any value is accepted when empty.")
+	flag.StringVar(&protectedNamespaces, "protected-namespaces", "default,kube-*",
+		"Comma separated names or glob patterns of the namespaces the operator must never manage.")
+	flag.StringVar(&protectedNamespacesFile, "protected-namespaces-file", "",
+		"File listing more protected namespace names or glob patterns, one per line.")
+	flag.BoolVar(&stripSafeFinalizers, "strip-safe-finalizers", false,
+		"Remove the finalizers of known-safe kinds, such as ConfigMaps and Secrets, "+
+			"left in managed namespaces stuck terminating.")
+	flag.StringVar(&operatorUsername, "operator-username", "",
+		"User the operator authenticates as. It may delete managed namespaces directly. "+
+			"Defaults to the service account the operator runs as.")
+	flag.StringVar(&namespaceDeletionGroups, "namespace-deletion-allowed-groups", "",
+		"Comma separated groups that may delete managed namespaces directly.")
+	flag.StringVar(&sourceNamespaces, "source-namespaces", "",
+		"Comma separated names or glob patterns of the namespaces Secrets, ConfigMaps and bootstrap bundles "+
+			"may be copied from. Sources elsewhere must carry the ric.com/replicable=true annotation.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,13 +118,88 @@ func main() {
 		os.Exit(1)
 	}
 
+	var costCenterRegexp *regexp.Regexp
+	if costCenterPattern != "" {
+		costCenterRegexp, err = regexp.Compile(costCenterPattern)
+		if err != nil {
+			setupLog.Error(err, "invalid cost center pattern")
+			os.Exit(1)
+		}
+	}
+
+	protected, err := namespaceHlp.ParsePatterns(protectedNamespaces)
+	if err != nil {
+		setupLog.Error(err, "invalid protected namespaces")
+		os.Exit(1)
+	}
+	if protectedNamespacesFile != "" {
+		data, err := os.ReadFile(protectedNamespacesFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read protected namespaces file")
+			os.Exit(1)
+		}
+		patterns, err := namespaceHlp.ParsePatterns(string(data))
+		if err != nil {
+			setupLog.Error(err, "invalid protected namespaces file")
+			os.Exit(1)
+		}
+		protected = append(protected, patterns...)
+	}
+	// The namespace the operator runs in is always protected
+	if namespace := operatorNamespace(); namespace != "" {
+		protected = append(protected, namespace)
+	} else {
+		setupLog.Info("unable to tell the namespace the operator runs in. It is not protected")
+	}
+
+	sources, err := namespaceHlp.ParsePatterns(sourceNamespaces)
+	if err != nil {
+		setupLog.Error(err, "invalid source namespaces")
+		os.Exit(1)
+	}
+
 	if err = (&controller.NamespaceConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		APIReader:           mgr.GetAPIReader(),
+		CostCenterPattern:   costCenterRegexp,
+		Recorder:            mgr.GetEventRecorderFor("namespaceconfig-controller"),
+		ProtectedNamespaces: protected,
+		Discovery:           memory.NewMemCacheClient(discovery.NewDiscoveryClientForConfigOrDie(mgr.GetConfig())),
+		StripSafeFinalizers: stripSafeFinalizers,
+		SourceNamespaces:    sources,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NamespaceConfig")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&controller.NamespaceConfigValidator{
+			ProtectedNamespaces: protected,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NamespaceConfig")
+			os.Exit(1)
+		}
+		var allowedGroups []string
+		for _, group := range strings.Split(namespaceDeletionGroups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				allowedGroups = append(allowedGroups, group)
+			}
+		}
+		if operatorUsername == "" {
+			operatorUsername = operatorServiceAccountUser()
+		}
+		if operatorUsername == "" {
+			setupLog.Info("unable to tell the user the operator authenticates as. " +
+				"It may not delete managed namespaces unless one of its groups is allowed")
+		}
+		if err = (&controller.NamespaceDeletionValidator{
+			OperatorUsername: operatorUsername,
+			AllowedGroups:    allowedGroups,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Namespace")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -113,3 +217,26 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// Tells the namespace the operator runs in, from the POD_NAMESPACE variable
+// set through the downward API or from the mounted service account token
+func operatorNamespace() string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	data, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Builds the user the operator authenticates as from the POD_SERVICE_ACCOUNT
+// variable set through the downward API
+func operatorServiceAccountUser() string {
+	namespace, serviceAccount := operatorNamespace(), os.Getenv("POD_SERVICE_ACCOUNT")
+	if namespace == "" || serviceAccount == "" {
+		return ""
+	}
+	return "system:serviceaccount:" + namespace + ":" + serviceAccount
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Field manager used when server-side applying objects into a namespace
+const fieldOwner = client.FieldOwner("ns-operator")
+
+// Splits a multi-document YAML or JSON stream into objects. Empty documents
+// are skipped.
+func decodeManifests(data string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// Server-side applies an object into nsName and returns its inventory entry.
+// Cluster scoped objects are refused, and so are kinds the cluster does not
+// serve or the operator may not manage. The object is not labeled as managed:
+// it is tracked by the inventory in the status, so pruning of the typed
+// objects never touches it.
+func (r *NamespaceConfigReconciler) applyObject(ctx context.Context, obj *unstructured.Unstructured, nsName string) (ricv1.AppliedObject, error) {
+	gvk := obj.GroupVersionKind()
+	entry := ricv1.AppliedObject{
+		Group:   gvk.Group,
+		Version: gvk.Version,
+		Kind:    gvk.Kind,
+		Name:    obj.GetName(),
+	}
+	if gvk.Kind == "" || gvk.Version == "" || obj.GetName() == "" {
+		return entry, fmt.Errorf("object %q is missing apiVersion, kind or name", obj.GetName())
+	}
+	// Unknown groups, versions and kinds all fail the mapping
+	namespaced, err := r.IsObjectNamespaced(obj)
+	if err != nil {
+		return entry, &rejectedError{
+			reason:  ricv1.ReasonUnsupportedResource,
+			message: fmt.Sprintf("%s %s cannot be applied: %s", gvk.Kind, obj.GetName(), err.Error()),
+		}
+	}
+	if !namespaced {
+		return entry, fmt.Errorf("%s %s is cluster scoped and cannot be applied into namespace %s", gvk.Kind, obj.GetName(), nsName)
+	}
+	obj.SetNamespace(nsName)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	if err := r.Patch(ctx, obj, client.Apply, fieldOwner, client.ForceOwnership); err != nil {
+		if errors.IsForbidden(err) {
+			return entry, &rejectedError{
+				reason: ricv1.ReasonUnsupportedResource,
+				message: fmt.Sprintf("%s %s cannot be applied into %s: the operator may not manage %s objects. "+
+					"Grant it RBAC on them or remove the object", gvk.Kind, obj.GetName(), nsName, gvk.GroupKind().String()),
+			}
+		}
+		return entry, err
+	}
+	return entry, nil
+}
+
+// Identifies an applied object regardless of the version it was applied with
+type inventoryKey struct {
+	group string
+	kind  string
+	name  string
+}
+
+func keyOf(entry ricv1.AppliedObject) inventoryKey {
+	return inventoryKey{group: entry.Group, kind: entry.Kind, name: entry.Name}
+}
+
+// Deletes the objects of the previous inventory that are not in the current
+// one. Entries are compared without their version, so moving a manifest to a
+// new apiVersion does not delete the object it just applied.
+func (r *NamespaceConfigReconciler) pruneInventory(ctx context.Context, previous []ricv1.AppliedObject, current []ricv1.AppliedObject, nsName string) error {
+	kept := make(map[inventoryKey]bool)
+	for _, entry := range current {
+		kept[keyOf(entry)] = true
+	}
+	for _, entry := range previous {
+		if kept[keyOf(entry)] {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Group: entry.Group, Version: entry.Version, Kind: entry.Kind})
+		obj.SetName(entry.Name)
+		obj.SetNamespace(nsName)
+		log.Log.Info(entry.Kind + " " + entry.Name + " no longer declared. Deleting it from " + nsName)
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
+		}
+	}
+	return nil
+}
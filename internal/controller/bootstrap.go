@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
+)
+
+// Field index on the namespace/name of the bootstrap ConfigMap of a CRD
+const bootstrapSourceIndex string = "spec.bootstrap.source"
+
+// Applies the manifests of the bootstrap ConfigMap into the namespace and
+// records what was applied in the status. Objects removed from it are pruned
+// by reconcileNamespaceContents.
+func (r *NamespaceConfigReconciler) reconcileBootstrap(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	var applied []ricv1.AppliedObject
+	ref := crdInstance.Spec.Bootstrap
+	if ref != nil {
+		var source corev1.ConfigMap
+		err := r.getSource(ctx, "Bootstrap ConfigMap", ref.Namespace, ref.Name, &source)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				// The watch on ConfigMaps brings us back once it shows up.
+				// Nothing gets pruned meanwhile.
+				log.Log.Info("Bootstrap ConfigMap " + sourceKey(ref.Namespace, ref.Name) + " not found. Skipping bootstrap of " + nsName)
+				return nil
+			}
+			return err
+		}
+		keys := namespaceHlp.SortedKeys(source.Data)
+		if ref.Key != "" {
+			if _, exists := source.Data[ref.Key]; !exists {
+				return fmt.Errorf("key %s not found in bootstrap ConfigMap %s", ref.Key, sourceKey(ref.Namespace, ref.Name))
+			}
+			keys = []string{ref.Key}
+		}
+		for _, key := range keys {
+			objects, err := decodeManifests(source.Data[key])
+			if err != nil {
+				return fmt.Errorf("could not decode key %s of bootstrap ConfigMap %s: %w", key, sourceKey(ref.Namespace, ref.Name), err)
+			}
+			for _, obj := range objects {
+				entry, err := r.applyObject(ctx, obj, nsName)
+				if err != nil {
+					return err
+				}
+				applied = append(applied, entry)
+			}
+		}
+	}
+	crdInstance.Status.BootstrapObjects = applied
+	return nil
+}
+
+// Indexes a CRD by the namespace/name of its bootstrap ConfigMap
+func indexBootstrapSource(obj client.Object) []string {
+	crdInstance, ok := obj.(*ricv1.NamespaceConfig)
+	if !ok || crdInstance.Spec.Bootstrap == nil {
+		return nil
+	}
+	return []string{sourceKey(crdInstance.Spec.Bootstrap.Namespace, crdInstance.Spec.Bootstrap.Name)}
+}
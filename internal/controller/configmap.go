@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Field index on the namespace/name of the ConfigMaps a CRD references
+const configMapSourceIndex string = "spec.configMaps.source"
+
+// Copies the ConfigMaps referenced in the CRD into the namespace and deletes
+// the copies whose reference was removed
+func (r *NamespaceConfigReconciler) reconcileConfigMaps(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	keep := make(map[string]bool)
+	for _, ref := range crdInstance.Spec.ConfigMaps {
+		targetName := replicaName(ref.SourceReference)
+		keep[targetName] = true
+		var source corev1.ConfigMap
+		err := r.getSource(ctx, "ConfigMap", ref.Namespace, ref.Name, &source)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				// The watch on ConfigMaps brings us back once it shows up
+				log.Log.Info("Source ConfigMap " + sourceKey(ref.Namespace, ref.Name) + " not found. Skipping copy into " + nsName)
+				continue
+			}
+			return err
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      targetName,
+				Namespace: nsName,
+			},
+		}
+		data, binaryData := filterConfigMapKeys(&source, ref.Keys)
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+			setManaged(configMap)
+			setSource(configMap, &source)
+			configMap.Data = data
+			configMap.BinaryData = binaryData
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Log.Info("ConfigMap " + targetName + " in " + nsName + " " + string(op) + " from " + sourceKey(ref.Namespace, ref.Name))
+		}
+	}
+	return r.pruneManaged(ctx, &corev1.ConfigMapList{}, nsName, keep)
+}
+
+// Selects and renames the keys of a source ConfigMap. Returns every key when
+// no mapping is given.
+func filterConfigMapKeys(source *corev1.ConfigMap, keys []ricv1.KeyMapping) (map[string]string, map[string][]byte) {
+	if len(keys) == 0 {
+		copied := source.DeepCopy()
+		return copied.Data, copied.BinaryData
+	}
+	var data map[string]string
+	var binaryData map[string][]byte
+	for _, mapping := range keys {
+		targetKey := mapping.TargetKey
+		if targetKey == "" {
+			targetKey = mapping.Key
+		}
+		if value, exists := source.Data[mapping.Key]; exists {
+			if data == nil {
+				data = make(map[string]string)
+			}
+			data[targetKey] = value
+		} else if value, exists := source.BinaryData[mapping.Key]; exists {
+			if binaryData == nil {
+				binaryData = make(map[string][]byte)
+			}
+			binaryData[targetKey] = append([]byte(nil), value...)
+		} else {
+			log.Log.Info("Key " + mapping.Key + " not found in ConfigMap " + sourceKey(source.Namespace, source.Name))
+		}
+	}
+	return data, binaryData
+}
+
+// Indexes a CRD by the namespace/name of every ConfigMap it references
+func indexConfigMapSources(obj client.Object) []string {
+	crdInstance, ok := obj.(*ricv1.NamespaceConfig)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, ref := range crdInstance.Spec.ConfigMaps {
+		keys = append(keys, sourceKey(ref.Namespace, ref.Name))
+	}
+	return keys
+}
+
+// Maps a ConfigMap to the CRDs that need to reconcile it. Copies route to the
+// CRD of their namespace, sources and bootstrap ConfigMaps fan out to every
+// CRD referencing them.
+func (r *NamespaceConfigReconciler) mapConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	if isManaged(obj) {
+		return r.requestsForNamespace(ctx, obj.GetNamespace())
+	}
+	requests := r.requestsForSource(ctx, configMapSourceIndex, obj)
+	return append(requests, r.requestsForSource(ctx, bootstrapSourceIndex, obj)...)
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Field index on the names of the namespaces a CRD generates
+const namespaceNameIndex string = "namespaceNames"
+
+// Indexes a CRD by the names of the namespaces it generates
+func indexNamespaceNames(obj client.Object) []string {
+	crdInstance, ok := obj.(*ricv1.NamespaceConfig)
+	if !ok {
+		return nil
+	}
+	targets, err := namespaceTargets(crdInstance)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// Describes who else owns a namespace. Returns an empty string when the CRD
+// may manage it: the namespace points back to the CRD, or it is managed by
+// the operator but predates the back-reference annotations.
+func foreignOwner(namespace *corev1.Namespace, crdInstance *ricv1.NamespaceConfig) string {
+	annotations := namespace.GetAnnotations()
+	if annotations[annOwnKey] != annOwnValue {
+		return "Namespace " + namespace.Name + " exists and is not managed by the operator"
+	}
+	if uid := annotations[annOwnerUID]; uid != "" && uid != string(crdInstance.UID) {
+		return "Namespace " + namespace.Name + " is owned by NamespaceConfig " +
+			sourceKey(annotations[annOwnerNamespace], annotations[annOwnerName])
+	}
+	return ""
+}
+
+// A namespace the CRD may take over from another owner
+type adoption struct {
+	name string
+	// Namespace/name of the CRD owning the namespace. Empty when unmanaged.
+	previousOwner string
+}
+
+// Checks that neither an existing namespace nor another CRD claims the
+// namespaces of the targets, honouring the adoption policy of the CRD.
+// Returns a message describing the first conflict, and the namespaces the
+// policy allows to take over.
+func (r *NamespaceConfigReconciler) detectConflicts(ctx context.Context, crdInstance *ricv1.NamespaceConfig, targets []target) (string, []adoption, error) {
+	var adoptions []adoption
+	for _, t := range targets {
+		var namespace corev1.Namespace
+		err := r.Get(ctx, types.NamespacedName{Name: t.name}, &namespace)
+		if client.IgnoreNotFound(err) != nil {
+			return "", nil, err
+		}
+		if err == nil {
+			annotations := namespace.GetAnnotations()
+			if owner := foreignOwner(&namespace, crdInstance); owner != "" {
+				switch {
+				case crdInstance.Spec.AdoptionPolicy == ricv1.AdoptionForce:
+					previousOwner := ""
+					if annotations[annOwnKey] == annOwnValue {
+						previousOwner = sourceKey(annotations[annOwnerNamespace], annotations[annOwnerName])
+					}
+					// Other CRDs generating the name lose it as well
+					adoptions = append(adoptions, adoption{name: t.name, previousOwner: previousOwner})
+					continue
+				case crdInstance.Spec.AdoptionPolicy == ricv1.AdoptionIfUnowned && annotations[annOwnKey] != annOwnValue:
+					adoptions = append(adoptions, adoption{name: t.name})
+				default:
+					return owner, nil, nil
+				}
+			} else if annotations[annOwnerUID] == string(crdInstance.UID) {
+				// The namespace points back to this CRD, so duplicates are the
+				// ones in conflict
+				continue
+			}
+		}
+		var crdList ricv1.NamespaceConfigList
+		if err := r.List(ctx, &crdList, client.MatchingFields{namespaceNameIndex: t.name}); err != nil {
+			return "", nil, err
+		}
+		for i := range crdList.Items {
+			other := &crdList.Items[i]
+			if other.UID == crdInstance.UID || !claimedFirst(other, crdInstance) {
+				continue
+			}
+			return "Namespace " + t.name + " is also generated by NamespaceConfig " + sourceKey(other.Namespace, other.Name), nil, nil
+		}
+	}
+	return "", adoptions, nil
+}
+
+// Records in the status and in an event that the CRD took over a namespace
+func (r *NamespaceConfigReconciler) recordAdoption(crdInstance *ricv1.NamespaceConfig, adopted adoption) {
+	message := "Adopted existing namespace " + adopted.name
+	if adopted.previousOwner != "" {
+		message += " from NamespaceConfig " + adopted.previousOwner
+	}
+	log.Log.Info(message)
+	r.recordEvent(crdInstance, corev1.EventTypeNormal, ricv1.ReasonAdopted, message)
+	record := ricv1.AdoptedNamespace{Name: adopted.name, PreviousOwner: adopted.previousOwner, AdoptedAt: metav1.Now()}
+	for i := range crdInstance.Status.AdoptedNamespaces {
+		if crdInstance.Status.AdoptedNamespaces[i].Name == adopted.name {
+			crdInstance.Status.AdoptedNamespaces[i] = record
+			return
+		}
+	}
+	crdInstance.Status.AdoptedNamespaces = append(crdInstance.Status.AdoptedNamespaces, record)
+}
+
+// Tells whether CRD a has precedence over CRD b on an unclaimed namespace:
+// the oldest one wins, then the first by namespace/name
+func claimedFirst(a *ricv1.NamespaceConfig, b *ricv1.NamespaceConfig) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return sourceKey(a.Namespace, a.Name) < sourceKey(b.Namespace, b.Name)
+}
+
+// Reports in the status the conflict found on the namespaces of the CRD
+func setConflictCondition(crdInstance *ricv1.NamespaceConfig, conflict string) {
+	if conflict == "" {
+		meta.RemoveStatusCondition(&crdInstance.Status.Conditions, ricv1.ConditionConflict)
+		return
+	}
+	meta.SetStatusCondition(&crdInstance.Status.Conditions, metav1.Condition{
+		Type:               ricv1.ConditionConflict,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: crdInstance.Generation,
+		Reason:             ricv1.ReasonNamespaceConflict,
+		Message:            conflict,
+	})
+}
+
+// Maps a namespace to the CRD that owns it and to every CRD generating its
+// name, so CRDs waiting on a conflict get another chance once it is gone
+func (r *NamespaceConfigReconciler) mapNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	requests := requestsForOwner(namespace)
+	var crdList ricv1.NamespaceConfigList
+	if err := r.List(ctx, &crdList, client.MatchingFields{namespaceNameIndex: namespace.GetName()}); err != nil {
+		log.Log.Error(err, "Could not list the CRDs generating namespace "+namespace.GetName())
+		return requests
+	}
+	for _, item := range crdList.Items {
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}}
+		if len(requests) == 0 || requests[0] != request {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
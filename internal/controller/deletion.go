@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Honours the deletion policy of a CRD being deleted on every namespace it
+// recorded in its status: its namespace, the namespaces of its environments
+// and the retired ones. The generated targets are only used when nothing was
+// recorded yet. Returns the namespaces still being deleted.
+func (r *NamespaceConfigReconciler) finalizeNamespaces(ctx context.Context, crdInstance *ricv1.NamespaceConfig, targets []target) ([]string, error) {
+	names := recordedNamespaces(crdInstance)
+	if len(names) == 0 {
+		for _, t := range targets {
+			names = append(names, t.name)
+		}
+	}
+	var terminating []string
+	seen := make(map[string]bool)
+	for _, nsName := range names {
+		if seen[nsName] {
+			continue
+		}
+		seen[nsName] = true
+		if r.isProtected(nsName) {
+			log.Log.Info("Namespace " + nsName + " is protected. Leaving it alone")
+			continue
+		}
+		// Namespaces claimed by another owner are left alone
+		conflict, adoptions, err := r.detectConflicts(ctx, crdInstance, []target{{name: nsName}})
+		if err != nil {
+			return nil, err
+		}
+		if conflict != "" || len(adoptions) > 0 {
+			log.Log.Info("Namespace " + nsName + " is not managed by " + crdInstance.Name + ". Leaving it alone")
+			continue
+		}
+		var namespace corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: nsName}, &namespace); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		pending, err := r.applyDeletionPolicy(ctx, crdInstance, &namespace)
+		if err != nil {
+			return nil, err
+		}
+		if pending {
+			terminating = append(terminating, nsName)
+		}
+	}
+	return terminating, nil
+}
+
+// Deletes, releases or keeps a namespace the CRD no longer manages, according
+// to its deletion policy, and reports what happened in an event. Returns true
+// while a deleted namespace is still terminating.
+func (r *NamespaceConfigReconciler) applyDeletionPolicy(ctx context.Context, crdInstance *ricv1.NamespaceConfig, namespace *corev1.Namespace) (bool, error) {
+	var reason, message string
+	switch crdInstance.Spec.DeletionPolicy {
+	case ricv1.DeletionRetain:
+		if err := r.releaseNamespace(ctx, crdInstance, namespace.Name); err != nil {
+			return false, err
+		}
+		reason, message = ricv1.ReasonNamespaceRetained, "Retained namespace "+namespace.Name+" without the ownership annotations"
+	case ricv1.DeletionOrphan:
+		reason, message = ricv1.ReasonNamespaceOrphaned, "Orphaned namespace "+namespace.Name+" as it is"
+	default:
+		if !namespace.DeletionTimestamp.IsZero() {
+			return true, nil
+		}
+		if err := r.Delete(ctx, namespace); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		reason, message = ricv1.ReasonNamespaceDeleted, "Deleted namespace "+namespace.Name
+	}
+	log.Log.Info(message)
+	r.recordEvent(crdInstance, corev1.EventTypeNormal, reason, message)
+	return reason == ricv1.ReasonNamespaceDeleted, nil
+}
+
+// Lists the namespaces the status of a CRD records as managed or retired
+func recordedNamespaces(crdInstance *ricv1.NamespaceConfig) []string {
+	var names []string
+	if crdInstance.Status.Namespace != "" {
+		names = append(names, crdInstance.Status.Namespace)
+	}
+	for _, env := range crdInstance.Status.Environments {
+		names = append(names, env.Namespace)
+	}
+	return append(names, crdInstance.Status.RetiredNamespaces...)
+}
+
+// Reports in the status that the CRD waits for its namespaces to be deleted
+func setTerminating(crdInstance *ricv1.NamespaceConfig, terminating []string) {
+	crdInstance.Status.Phase = ricv1.PhaseTerminating
+	crdInstance.Status.TerminatingNamespaces = terminating
+	if crdInstance.Status.TerminationStartedAt == nil {
+		now := metav1.Now()
+		crdInstance.Status.TerminationStartedAt = &now
+	}
+	crdInstance.Status.TerminationElapsed = time.Since(crdInstance.Status.TerminationStartedAt.Time).Round(time.Second).String()
+}
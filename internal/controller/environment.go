@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// A namespace managed by a CRD
+type target struct {
+	// Environment the namespace belongs to. Empty without environments.
+	env string
+	// Name of the namespace
+	name string
+	// Name before truncation. Empty when the name was not truncated.
+	truncatedFrom string
+	// Copy of the CRD with the overrides of the environment applied
+	instance *ricv1.NamespaceConfig
+}
+
+// Returns the set of the namespace names of the targets
+func targetNames(targets []target) map[string]bool {
+	names := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		names[t.name] = true
+	}
+	return names
+}
+
+// Lists the namespaces a CRD manages: one per environment, or a single one
+// when the CRD has no environments
+func namespaceTargets(crdInstance *ricv1.NamespaceConfig) ([]target, error) {
+	if len(crdInstance.Spec.Environments) == 0 {
+		name, truncatedFrom, err := namespaceName(crdInstance, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []target{{name: name, truncatedFrom: truncatedFrom, instance: crdInstance.DeepCopy()}}, nil
+	}
+	targets := make([]target, 0, len(crdInstance.Spec.Environments))
+	seen := make(map[string]string)
+	for i := range crdInstance.Spec.Environments {
+		env := &crdInstance.Spec.Environments[i]
+		name, truncatedFrom, err := namespaceName(crdInstance, env)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %w", env.Name, err)
+		}
+		if other, exists := seen[name]; exists {
+			return nil, fmt.Errorf("environments %s and %s both generate namespace %s", other, env.Name, name)
+		}
+		seen[name] = env.Name
+		targets = append(targets, target{
+			env:           env.Name,
+			name:          name,
+			truncatedFrom: truncatedFrom,
+			instance:      withEnvironment(crdInstance, env),
+		})
+	}
+	return targets, nil
+}
+
+// Returns a copy of the CRD with the overrides of an environment applied
+func withEnvironment(crdInstance *ricv1.NamespaceConfig, env *ricv1.Environment) *ricv1.NamespaceConfig {
+	instance := crdInstance.DeepCopy()
+	if env.Prefix != "" {
+		instance.Spec.NamespacePrefix = env.Prefix
+	}
+	if len(env.Labels) > 0 && instance.Spec.Labels == nil {
+		instance.Spec.Labels = make(map[string]string)
+	}
+	for key, value := range env.Labels {
+		instance.Spec.Labels[key] = value
+	}
+	if len(env.Annotations) > 0 && instance.Spec.Annotations == nil {
+		instance.Spec.Annotations = make(map[string]string)
+	}
+	for key, value := range env.Annotations {
+		instance.Spec.Annotations[key] = value
+	}
+	if env.ResourceQuota != nil {
+		instance.Spec.ResourceQuota = env.ResourceQuota.DeepCopy()
+	}
+	return instance
+}
+
+// Builds the status of the environment of a target
+func environmentStatus(t target, reconcileErr error) ricv1.EnvironmentStatus {
+	status := ricv1.EnvironmentStatus{
+		Name:      t.env,
+		Namespace: t.name,
+		Ready:     reconcileErr == nil,
+	}
+	if reconcileErr != nil {
+		status.Message = reconcileErr.Error()
+	}
+	return status
+}
+
+// Applies the deletion policy to the namespaces of the environments recorded
+// in the status that are no longer among the targets. Renamed environments,
+// and all of them when the CRD drops its environments, are left to the name
+// change policy. Returns the status of the removed environments whose policy
+// has not completed yet, so they stay recorded and are retried.
+func (r *NamespaceConfigReconciler) pruneEnvironments(ctx context.Context, crdInstance *ricv1.NamespaceConfig, targets []target) ([]ricv1.EnvironmentStatus, error) {
+	// Dropping every environment is a name change, left to its policy
+	if len(crdInstance.Spec.Environments) == 0 {
+		return nil, nil
+	}
+	wanted := make(map[string]bool)
+	for _, t := range targets {
+		if t.env != "" {
+			wanted[t.env] = true
+		}
+	}
+	current := targetNames(targets)
+	var pending []ricv1.EnvironmentStatus
+	var firstErr error
+	for _, env := range crdInstance.Status.Environments {
+		// A renamed environment may still generate the same namespace
+		if wanted[env.Name] || current[env.Namespace] || r.isProtected(env.Namespace) {
+			continue
+		}
+		removed := env
+		removed.Ready = false
+		deleting, err := r.pruneEnvironment(ctx, crdInstance, env)
+		if err != nil {
+			removed.Message = err.Error()
+			pending = append(pending, removed)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if deleting {
+			removed.Message = "Environment removed from the spec. Waiting for namespace " + env.Namespace + " to be deleted"
+			pending = append(pending, removed)
+		}
+	}
+	return pending, firstErr
+}
+
+// Applies the deletion policy to the namespace of a removed environment.
+// Returns true while the namespace is still being deleted.
+func (r *NamespaceConfigReconciler) pruneEnvironment(ctx context.Context, crdInstance *ricv1.NamespaceConfig, env ricv1.EnvironmentStatus) (bool, error) {
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: env.Namespace}, &namespace); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if foreignOwner(&namespace, crdInstance) != "" {
+		return false, nil
+	}
+	if namespace.DeletionTimestamp.IsZero() {
+		log.Log.Info("Environment " + env.Name + " removed. Applying the deletion policy to namespace " + env.Namespace)
+	}
+	return r.applyDeletionPolicy(ctx, crdInstance, &namespace)
+}
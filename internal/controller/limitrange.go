@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const limitRangeName string = "ns-operator-limits"
+
+// Creates or updates the LimitRange declared in the CRD. Deletes it when the
+// CRD no longer declares one.
+func (r *NamespaceConfigReconciler) reconcileLimitRange(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      limitRangeName,
+			Namespace: nsName,
+		},
+	}
+	if crdInstance.Spec.LimitRange == nil {
+		err := r.Get(ctx, types.NamespacedName{Namespace: nsName, Name: limitRangeName}, limitRange)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !isManaged(limitRange) {
+			return nil
+		}
+		log.Log.Info("LimitRange removed from spec. Deleting it from " + nsName)
+		if err := r.Delete(ctx, limitRange); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, limitRange, func() error {
+		setManaged(limitRange)
+		limitRange.Spec = renderLimitRange(crdInstance.Spec.LimitRange)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Log.Info("LimitRange " + limitRangeName + " in " + nsName + " " + string(op))
+	}
+	return nil
+}
+
+// Translates the CRD limits into a LimitRangeSpec
+func renderLimitRange(spec *ricv1.LimitRangeSpec) corev1.LimitRangeSpec {
+	var rendered corev1.LimitRangeSpec
+	if spec.Container != nil {
+		item := corev1.LimitRangeItem{
+			Type:           corev1.LimitTypeContainer,
+			Default:        spec.Container.Default.DeepCopy(),
+			DefaultRequest: spec.Container.DefaultRequest.DeepCopy(),
+			Min:            spec.Container.Min.DeepCopy(),
+			Max:            spec.Container.Max.DeepCopy(),
+		}
+		defaultContainerLimits(&item)
+		rendered.Limits = append(rendered.Limits, item)
+	}
+	if spec.Pod != nil {
+		rendered.Limits = append(rendered.Limits, corev1.LimitRangeItem{
+			Type: corev1.LimitTypePod,
+			Min:  spec.Pod.Min.DeepCopy(),
+			Max:  spec.Pod.Max.DeepCopy(),
+		})
+	}
+	if spec.PersistentVolumeClaim != nil {
+		item := corev1.LimitRangeItem{Type: corev1.LimitTypePersistentVolumeClaim}
+		if spec.PersistentVolumeClaim.Min != nil {
+			item.Min = corev1.ResourceList{corev1.ResourceStorage: spec.PersistentVolumeClaim.Min.DeepCopy()}
+		}
+		if spec.PersistentVolumeClaim.Max != nil {
+			item.Max = corev1.ResourceList{corev1.ResourceStorage: spec.PersistentVolumeClaim.Max.DeepCopy()}
+		}
+		rendered.Limits = append(rendered.Limits, item)
+	}
+	return rendered
+}
+
+// Applies the same defaulting the API server does on container limits, so the
+// rendered spec matches the live object and does not trigger endless updates
+func defaultContainerLimits(item *corev1.LimitRangeItem) {
+	if item.Default == nil {
+		item.Default = make(corev1.ResourceList)
+	}
+	if item.DefaultRequest == nil {
+		item.DefaultRequest = make(corev1.ResourceList)
+	}
+	for key, value := range item.Max {
+		if _, exists := item.Default[key]; !exists {
+			item.Default[key] = value.DeepCopy()
+		}
+	}
+	for key, value := range item.Default {
+		if _, exists := item.DefaultRequest[key]; !exists {
+			item.DefaultRequest[key] = value.DeepCopy()
+		}
+	}
+	for key, value := range item.Min {
+		if _, exists := item.DefaultRequest[key]; !exists {
+			item.DefaultRequest[key] = value.DeepCopy()
+		}
+	}
+}
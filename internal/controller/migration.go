@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
+)
+
+// A managed namespace whose generated name changed
+type nameChange struct {
+	from string
+	to   string
+}
+
+// Compares the namespaces recorded in the status with the targets and returns
+// the ones whose name changed. Namespaces still among the targets, such as
+// when two environments swap names, are not changes.
+func detectNameChanges(crdInstance *ricv1.NamespaceConfig, targets []target) []nameChange {
+	current := targetNames(targets)
+	var changes []nameChange
+	for _, change := range recordedNameChanges(crdInstance, targets) {
+		if !current[change.from] {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Pairs every namespace recorded in the status with the target replacing it
+func recordedNameChanges(crdInstance *ricv1.NamespaceConfig, targets []target) []nameChange {
+	var changes []nameChange
+	if len(crdInstance.Spec.Environments) == 0 {
+		if recorded := crdInstance.Status.Namespace; recorded != "" && recorded != targets[0].name {
+			changes = append(changes, nameChange{from: recorded, to: targets[0].name})
+		}
+		// The CRD dropped its environments, so their namespaces are replaced
+		for _, env := range crdInstance.Status.Environments {
+			if env.Namespace != targets[0].name {
+				changes = append(changes, nameChange{from: env.Namespace, to: targets[0].name})
+			}
+		}
+		return changes
+	}
+	// The CRD switched to environments, so its single namespace is replaced
+	if recorded := crdInstance.Status.Namespace; recorded != "" {
+		var names []string
+		for _, t := range targets {
+			names = append(names, t.name)
+		}
+		changes = append(changes, nameChange{from: recorded, to: strings.Join(names, ", ")})
+	}
+	recordedEnvs := make(map[string]string)
+	for _, env := range crdInstance.Status.Environments {
+		recordedEnvs[env.Name] = env.Namespace
+	}
+	for _, t := range targets {
+		if recorded, exists := recordedEnvs[t.env]; exists && recorded != t.name {
+			changes = append(changes, nameChange{from: recorded, to: t.name})
+		}
+	}
+	return changes
+}
+
+// Applies the name change policy of the CRD. Returns false when the policy
+// blocks the change and nothing may be written.
+func (r *NamespaceConfigReconciler) handleNameChanges(ctx context.Context, crdInstance *ricv1.NamespaceConfig, changes []nameChange) (bool, error) {
+	if len(changes) == 0 {
+		return true, nil
+	}
+	switch crdInstance.Spec.NameChangePolicy {
+	case ricv1.NameChangeKeep:
+		for _, change := range changes {
+			log.Log.Info("Namespace name changed from " + change.from + " to " + change.to + ". Releasing " + change.from)
+			if err := r.releaseNamespace(ctx, crdInstance, change.from); err != nil {
+				return true, err
+			}
+		}
+	case ricv1.NameChangeMigrate:
+		for _, change := range changes {
+			log.Log.Info("Namespace name changed from " + change.from + " to " + change.to + ". Retiring " + change.from)
+			if !namespaceHlp.ContainsString(crdInstance.Status.RetiredNamespaces, change.from) {
+				crdInstance.Status.RetiredNamespaces = append(crdInstance.Status.RetiredNamespaces, change.from)
+			}
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Strips the ownership annotations from a namespace of the CRD so the operator
+// no longer manages it
+func (r *NamespaceConfigReconciler) releaseNamespace(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	if r.isProtected(nsName) {
+		return nil
+	}
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: nsName}, &namespace); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if foreignOwner(&namespace, crdInstance) != "" {
+		return nil
+	}
+	patch := client.MergeFrom(namespace.DeepCopy())
+	annotations := namespace.GetAnnotations()
+	delete(annotations, annOwnKey)
+	delete(annotations, annOwnerNamespace)
+	delete(annotations, annOwnerName)
+	delete(annotations, annOwnerUID)
+	delete(annotations, annManagedKeys)
+	delete(annotations, annForeignLabels)
+	namespace.SetAnnotations(annotations)
+	return r.Patch(ctx, &namespace, patch)
+}
+
+// Deletes the retired namespaces that are empty and drops the ones that are
+// gone from the status. Namespaces that are targets again, such as after a
+// rename is reverted, are no longer retired.
+func (r *NamespaceConfigReconciler) reconcileRetiredNamespaces(ctx context.Context, crdInstance *ricv1.NamespaceConfig, targets []target) error {
+	current := targetNames(targets)
+	var remaining []string
+	for _, nsName := range crdInstance.Status.RetiredNamespaces {
+		if current[nsName] {
+			log.Log.Info("Retired namespace " + nsName + " is generated again. No longer retiring it")
+			continue
+		}
+		var namespace corev1.Namespace
+		if err := r.Get(ctx, types.NamespacedName{Name: nsName}, &namespace); err != nil {
+			if errors.IsNotFound(err) {
+				log.Log.Info("Retired namespace " + nsName + " is gone")
+				continue
+			}
+			return err
+		}
+		if owner := foreignOwner(&namespace, crdInstance); owner != "" {
+			log.Log.Info(owner + ". No longer retiring it")
+			continue
+		}
+		if r.isProtected(nsName) {
+			log.Log.Info("Retired namespace " + nsName + " is protected. No longer retiring it")
+			continue
+		}
+		remaining = append(remaining, nsName)
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		empty, err := r.isNamespaceEmpty(ctx, nsName)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			log.Log.Info("Retired namespace " + nsName + " still holds workloads. Waiting before deleting it")
+			continue
+		}
+		log.Log.Info("Retired namespace " + nsName + " is empty. Deleting it")
+		if err := r.Delete(ctx, &namespace); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	crdInstance.Status.RetiredNamespaces = remaining
+	return nil
+}
+
+// Checks that a namespace holds no pods nor persistent volume claims. Reads
+// from the API server so the cache does not have to hold every pod.
+func (r *NamespaceConfigReconciler) isNamespaceEmpty(ctx context.Context, nsName string) (bool, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	var pods corev1.PodList
+	if err := reader.List(ctx, &pods, client.InNamespace(nsName), client.Limit(1)); err != nil {
+		return false, err
+	}
+	if len(pods.Items) > 0 {
+		return false, nil
+	}
+	var claims corev1.PersistentVolumeClaimList
+	if err := reader.List(ctx, &claims, client.InNamespace(nsName), client.Limit(1)); err != nil {
+		return false, err
+	}
+	return len(claims.Items) == 0, nil
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Path the namespace deletion webhook is served on
+const namespaceWebhookPath string = "/validate-v1-namespace"
+
+// NamespaceDeletionValidator denies the direct deletion of managed namespaces.
+// They are deleted through their NamespaceConfig instead.
+type NamespaceDeletionValidator struct {
+	// OperatorUsername is the user the operator authenticates as, such as
+	// system:serviceaccount:<namespace>:<service account>. Nobody is exempted
+	// by name when empty.
+	OperatorUsername string
+	// AllowedGroups may delete managed namespaces directly
+	AllowedGroups []string
+	decoder       *admission.Decoder
+	// Reads the NamespaceConfigs the namespaces point back to
+	reader client.Reader
+}
+
+// Failing open keeps namespaces deletable cluster-wide while the operator is down
+//+kubebuilder:webhook:path=/validate-v1-namespace,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=namespaces,verbs=delete,versions=v1,name=vnamespace.ric.com,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the namespace deletion webhook on the
+// webhook server of the manager
+func (v *NamespaceDeletionValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	v.decoder = admission.NewDecoder(mgr.GetScheme())
+	v.reader = mgr.GetAPIReader()
+	mgr.GetWebhookServer().Register(namespaceWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// Handle denies the deletion of namespaces carrying the ownership annotation,
+// unless the operator or an allowed group asks for it, or the NamespaceConfig
+// the namespace points back to is gone, such as after an Orphan deletion
+func (v *NamespaceDeletionValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Delete {
+		return admission.Allowed("")
+	}
+	var namespace corev1.Namespace
+	if err := v.decoder.DecodeRaw(req.OldObject, &namespace); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	annotations := namespace.GetAnnotations()
+	if annotations[annOwnKey] != annOwnValue {
+		return admission.Allowed("")
+	}
+	if v.OperatorUsername != "" && req.UserInfo.Username == v.OperatorUsername {
+		return admission.Allowed("")
+	}
+	for _, group := range req.UserInfo.Groups {
+		for _, allowed := range v.AllowedGroups {
+			if group == allowed {
+				return admission.Allowed("")
+			}
+		}
+	}
+	owner := "the operator"
+	if annotations[annOwnerName] != "" {
+		owned, err := v.ownerExists(ctx, annotations)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !owned {
+			return admission.Allowed("")
+		}
+		owner = "NamespaceConfig " + sourceKey(annotations[annOwnerNamespace], annotations[annOwnerName])
+	}
+	return admission.Denied("namespace " + namespace.Name + " is managed by " + owner +
+		". Delete it through its NamespaceConfig instead")
+}
+
+// Tells whether the NamespaceConfig a namespace points back to still exists.
+// A NamespaceConfig recreated under the same name is another owner.
+func (v *NamespaceDeletionValidator) ownerExists(ctx context.Context, annotations map[string]string) (bool, error) {
+	var crdInstance ricv1.NamespaceConfig
+	key := types.NamespacedName{Namespace: annotations[annOwnerNamespace], Name: annotations[annOwnerName]}
+	if err := v.reader.Get(ctx, key, &crdInstance); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	uid := annotations[annOwnerUID]
+	return uid == "" || uid == string(crdInstance.UID), nil
+}
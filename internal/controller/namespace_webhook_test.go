@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const testOperatorUsername = "system:serviceaccount:operator-ric:controller-manager"
+
+// Builds the admission request of a user acting on a namespace
+func namespaceRequest(t *testing.T, operation admissionv1.Operation, annotations map[string]string, user authenticationv1.UserInfo) admission.Request {
+	t.Helper()
+	raw, err := json.Marshal(&corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: annotations},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: operation,
+		Name:      "team-a",
+		UserInfo:  user,
+		OldObject: runtime.RawExtension{Raw: raw},
+	}}
+}
+
+// Builds a validator whose only NamespaceConfig is teams/payments
+func newTestValidator(t *testing.T) *NamespaceDeletionValidator {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := ricv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	owner := &ricv1.NamespaceConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "teams", Name: "payments", UID: types.UID("payments-uid")}}
+	return &NamespaceDeletionValidator{
+		decoder: admission.NewDecoder(scheme),
+		reader:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner).Build(),
+	}
+}
+
+func TestNamespaceDeletionValidator(t *testing.T) {
+	validator := newTestValidator(t)
+	validator.OperatorUsername = testOperatorUsername
+	validator.AllowedGroups = []string{"platform-admins"}
+	managed := map[string]string{
+		annOwnKey:         annOwnValue,
+		annOwnerNamespace: "teams",
+		annOwnerName:      "payments",
+		annOwnerUID:       "payments-uid",
+	}
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		annotations map[string]string
+		user        authenticationv1.UserInfo
+		allowed     bool
+	}{
+		{
+			name:      "unmanaged namespace",
+			operation: admissionv1.Delete,
+			user:      authenticationv1.UserInfo{Username: "jane"},
+			allowed:   true,
+		},
+		{
+			name:        "operator",
+			operation:   admissionv1.Delete,
+			annotations: managed,
+			user:        authenticationv1.UserInfo{Username: testOperatorUsername},
+			allowed:     true,
+		},
+		{
+			name:        "allowed group",
+			operation:   admissionv1.Delete,
+			annotations: managed,
+			user:        authenticationv1.UserInfo{Username: "jane", Groups: []string{"developers", "platform-admins"}},
+			allowed:     true,
+		},
+		{
+			name:        "other user",
+			operation:   admissionv1.Delete,
+			annotations: managed,
+			user:        authenticationv1.UserInfo{Username: "jane", Groups: []string{"developers"}},
+			allowed:     false,
+		},
+		{
+			name:      "orphaned namespace",
+			operation: admissionv1.Delete,
+			annotations: map[string]string{
+				annOwnKey:         annOwnValue,
+				annOwnerNamespace: "teams",
+				annOwnerName:      "deleted",
+				annOwnerUID:       "deleted-uid",
+			},
+			user:    authenticationv1.UserInfo{Username: "jane"},
+			allowed: true,
+		},
+		{
+			name:      "owner recreated under the same name",
+			operation: admissionv1.Delete,
+			annotations: map[string]string{
+				annOwnKey:         annOwnValue,
+				annOwnerNamespace: "teams",
+				annOwnerName:      "payments",
+				annOwnerUID:       "previous-uid",
+			},
+			user:    authenticationv1.UserInfo{Username: "jane"},
+			allowed: true,
+		},
+		{
+			name:        "not a deletion",
+			operation:   admissionv1.Update,
+			annotations: managed,
+			user:        authenticationv1.UserInfo{Username: "jane"},
+			allowed:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := validator.Handle(context.Background(), namespaceRequest(t, tt.operation, tt.annotations, tt.user))
+			if response.Allowed != tt.allowed {
+				t.Fatalf("Handle() allowed = %v, want %v: %v", response.Allowed, tt.allowed, response.Result)
+			}
+		})
+	}
+}
+
+func TestNamespaceDeletionValidatorNamesOwner(t *testing.T) {
+	validator := newTestValidator(t)
+	request := namespaceRequest(t, admissionv1.Delete, map[string]string{
+		annOwnKey:         annOwnValue,
+		annOwnerNamespace: "teams",
+		annOwnerName:      "payments",
+	}, authenticationv1.UserInfo{Username: testOperatorUsername})
+	response := validator.Handle(context.Background(), request)
+	if response.Allowed {
+		t.Fatal("Handle() allowed the deletion without an operator username")
+	}
+	want := "namespace team-a is managed by NamespaceConfig teams/payments. Delete it through its NamespaceConfig instead"
+	if response.Result == nil || response.Result.Message != want {
+		t.Fatalf("Handle() message = %v, want %q", response.Result, want)
+	}
+}
@@ -18,14 +18,25 @@ package controller
 
 import (
 	"context"
+	stderrors "errors"
+	"regexp"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -38,18 +49,83 @@ import (
 type NamespaceConfigReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// APIReader reads uncached objects, such as the pods of a namespace
+	APIReader client.Reader
+	// CostCenterPattern restricts spec.owner.costCenter when set
+	CostCenterPattern *regexp.Regexp
+	// Recorder publishes events on the CRD
+	Recorder record.EventRecorder
+	// ProtectedNamespaces holds the exact names and glob patterns of the
+	// namespaces the operator must never create, update nor delete
+	ProtectedNamespaces []string
+	// Discovery lists the resources left in namespaces stuck terminating. A
+	// cached client is invalidated once per diagnosis pass.
+	Discovery discovery.DiscoveryInterface
+	// StripSafeFinalizers removes the finalizers of known-safe kinds from the
+	// objects left in namespaces stuck terminating
+	StripSafeFinalizers bool
+	// SourceNamespaces holds the exact names and glob patterns of the
+	// namespaces Secrets, ConfigMaps and bootstrap bundles may be copied from.
+	// Sources elsewhere must opt in through the ric.com/replicable annotation.
+	SourceNamespaces []string
+}
+
+// An error caused by the spec that retrying does not fix. It is reported
+// with its own reason instead of ReconcileFailed.
+type rejectedError struct {
+	reason  string
+	message string
+}
+
+func (e *rejectedError) Error() string {
+	return e.message
 }
 
 const (
 	annOwnKey    string = "ric.com/owner"
 	annOwnValue  string = "ns-operator"
 	crdFinalizer string = "ric.com/namespaceconfig"
+	// How often retired namespaces are checked for emptiness
+	retiredNamespaceRequeue = time.Minute
+	// How often the deletion of the namespaces of a deleted CRD is checked
+	namespaceTerminationRequeue = 10 * time.Second
+	// How often a rejected spec is retried. Granting the operator RBAC or
+	// installing a CRD triggers no event.
+	rejectedRequeue = time.Minute
+	// Annotations pointing a namespace back to the CRD that owns it
+	annOwnerNamespace string = "ric.com/owner-namespace"
+	annOwnerName      string = "ric.com/owner-name"
+	annOwnerUID       string = "ric.com/owner-uid"
+	// Annotation listing the namespace annotations set by the operator, so the
+	// ones removed from the CRD can be told apart from foreign ones
+	annManagedKeys string = "ric.com/managed-annotations"
+	// Annotation listing the labels a namespace had when it was adopted. They
+	// are kept, every other label is enforced.
+	annForeignLabels string = "ric.com/foreign-labels"
+	// Label set on every object the operator creates inside a managed namespace
+	lblManagedKey   string = "ric.com/managed-by"
+	lblManagedValue string = "ns-operator"
 )
 
 //+kubebuilder:rbac:groups=ric.ric.com,resources=namespaceconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ric.ric.com,resources=namespaceconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ric.ric.com,resources=namespaceconfigs/finalizers,verbs=get;list;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=limitranges,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind
+//+kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims,verbs=list
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// Bootstrap Roles may grant more than the operator holds, and bootstrap
+// RoleBindings may reference them
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=*,resources=*,verbs=list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -63,15 +139,8 @@ const (
 
 func (r *NamespaceConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = log.FromContext(ctx)
-	annotations := make(map[string]string)
-	annotations[annOwnKey] = annOwnValue
 
 	crdInstance := &ricv1.NamespaceConfig{}
-	var namespace corev1.Namespace
-
-	workingNs := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{},
-	}
 	err := r.Get(ctx, req.NamespacedName, crdInstance)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -82,14 +151,26 @@ func (r *NamespaceConfigReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			return ctrl.Result{}, err
 		}
 	}
-	nsFullName := namespaceHlp.GenerateNamespaceName(crdInstance.Name, crdInstance.Spec.NamespacePrefix)
-	labelsInCrd := crdInstance.Spec.Labels
-	workingNs.SetName(nsFullName)
-	workingNs.SetLabels(labelsInCrd)
-	workingNs.SetAnnotations(annotations)
-	// workingNs.Finalizers = append(workingNs.Finalizers, crdFinalizer)
+	originalStatus := crdInstance.Status.DeepCopy()
+	targets, err := namespaceTargets(crdInstance)
+	if err != nil {
+		log.Log.Error(err, "Could not generate the namespace names of "+crdInstance.Name)
+		// A CRD being deleted falls back to the namespaces recorded in its
+		// status, so an invalid spec cannot keep it terminating forever
+		if crdInstance.DeletionTimestamp.IsZero() {
+			setReadyCondition(crdInstance, metav1.ConditionFalse, ricv1.ReasonInvalidName, err.Error())
+			return ctrl.Result{}, r.updateStatus(ctx, crdInstance, originalStatus)
+		}
+	}
+	setTruncatedCondition(crdInstance, targets)
 	// Check if its not being deleted and needs the finalizer field to be set
 	if crdInstance.DeletionTimestamp.IsZero() {
+		// Refuses protected namespaces before anything is written
+		if protected := protectedTargets(targets, r.ProtectedNamespaces); protected != "" {
+			log.Log.Info("Refusing " + crdInstance.Name + ": " + protected)
+			setReadyCondition(crdInstance, metav1.ConditionFalse, ricv1.ReasonProtectedNamespace, protected)
+			return ctrl.Result{}, r.updateStatus(ctx, crdInstance, originalStatus)
+		}
 		if !namespaceHlp.ContainsString(crdInstance.Finalizers, crdFinalizer) {
 			log.Log.Info("Adding finalizer to CRD ", "finalizer", crdFinalizer)
 			crdInstance.Finalizers = append(crdInstance.Finalizers, crdFinalizer)
@@ -98,38 +179,129 @@ func (r *NamespaceConfigReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 				return ctrl.Result{}, err
 			}
 		}
-		err = r.Client.Get(ctx, types.NamespacedName{Name: nsFullName}, &namespace)
+		crdInstance.Status.Phase = ricv1.PhaseActive
+		// Rejects specs the schema could not catch before writing anything
+		if err = r.validateSpec(crdInstance); err != nil {
+			log.Log.Info("Invalid spec in " + crdInstance.Name + ": " + err.Error())
+			setReadyCondition(crdInstance, metav1.ConditionFalse, ricv1.ReasonInvalidSpec, err.Error())
+			return ctrl.Result{}, r.updateStatus(ctx, crdInstance, originalStatus)
+		}
+		// Refuses to write namespaces another owner claims
+		conflict, adoptions, err := r.detectConflicts(ctx, crdInstance, targets)
 		if err != nil {
-			if client.IgnoreNotFound(err) != nil {
-				log.Log.Error(err, "Error getting Namespace")
-				return ctrl.Result{}, err
+			log.Log.Error(err, "Could not check the namespaces of "+crdInstance.Name+" for conflicts")
+			return ctrl.Result{}, err
+		}
+		setConflictCondition(crdInstance, conflict)
+		if conflict != "" {
+			log.Log.Info("Conflict in " + crdInstance.Name + ": " + conflict)
+			setReadyCondition(crdInstance, metav1.ConditionFalse, ricv1.ReasonNamespaceConflict, conflict)
+			return ctrl.Result{}, r.updateStatus(ctx, crdInstance, originalStatus)
+		}
+		// Applies the name change policy before touching any namespace
+		changes := detectNameChanges(crdInstance, targets)
+		proceed, err := r.handleNameChanges(ctx, crdInstance, changes)
+		if err != nil {
+			log.Log.Error(err, "Could not apply the name change policy of "+crdInstance.Name)
+			return ctrl.Result{}, err
+		}
+		if !proceed {
+			var described []string
+			for _, change := range changes {
+				described = append(described, change.from+" to "+change.to)
+			}
+			message := "Spec renames namespace " + strings.Join(described, ", ") +
+				". Revert the change or set nameChangePolicy to Keep or Migrate"
+			log.Log.Info(message)
+			setReadyCondition(crdInstance, metav1.ConditionFalse, ricv1.ReasonNameChangeBlocked, message)
+			return ctrl.Result{}, r.updateStatus(ctx, crdInstance, originalStatus)
+		}
+		// A failing namespace does not stop the others from being reconciled
+		var reconcileErr error
+		var envStatuses []ricv1.EnvironmentStatus
+		var nsNames []string
+		adopting := make(map[string]adoption)
+		for _, adopted := range adoptions {
+			adopting[adopted.name] = adopted
+		}
+		for _, t := range targets {
+			err := r.reconcileNamespace(ctx, t.instance, t.name)
+			if adopted, exists := adopting[t.name]; exists && err == nil {
+				r.recordAdoption(crdInstance, adopted)
+			}
+			if t.env != "" {
+				envStatuses = append(envStatuses, environmentStatus(t, err))
+			}
+			if err != nil && reconcileErr == nil {
+				reconcileErr = err
 			}
-			log.Log.Info("Namespace " + nsFullName + " does not exists. Creating it")
-			// Create ns because it does not exists
-			if err = r.Create(ctx, workingNs); err != nil {
-				log.Log.Error(err, "Namespace could not be created: "+nsFullName)
+			nsNames = append(nsNames, t.name)
+		}
+		removedEnvs, err := r.pruneEnvironments(ctx, crdInstance, targets)
+		if err != nil {
+			log.Log.Error(err, "Could not delete the namespaces of removed environments of "+crdInstance.Name)
+			if reconcileErr == nil {
+				reconcileErr = err
+			}
+		}
+		// Removed environments stay recorded until their deletion policy
+		// completes, so they are retried and finalized with the CRD
+		crdInstance.Status.Environments = append(envStatuses, removedEnvs...)
+		crdInstance.Status.Namespace = ""
+		if len(crdInstance.Spec.Environments) == 0 {
+			crdInstance.Status.Namespace = targets[0].name
+		}
+		if err = r.reconcileRetiredNamespaces(ctx, crdInstance, targets); err != nil {
+			log.Log.Error(err, "Could not clean up the retired namespaces of "+crdInstance.Name)
+			if reconcileErr == nil {
+				reconcileErr = err
+			}
+		}
+		if reconcileErr != nil {
+			// Rejected specs are retried slowly instead of with backoff
+			var rejected *rejectedError
+			if stderrors.As(reconcileErr, &rejected) {
+				setReadyCondition(crdInstance, metav1.ConditionFalse, rejected.reason, rejected.message)
+				if err = r.updateStatus(ctx, crdInstance, originalStatus); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: rejectedRequeue}, nil
+			}
+			setReadyCondition(crdInstance, metav1.ConditionFalse, ricv1.ReasonReconcileFailed, reconcileErr.Error())
+			if err = r.updateStatus(ctx, crdInstance, originalStatus); err != nil {
 				return ctrl.Result{}, err
 			}
-			log.Log.Info("Namespace " + nsFullName + " created and labeled with " + namespaceHlp.MapToStrings(labelsInCrd))
-			return ctrl.Result{}, nil
+			return ctrl.Result{}, reconcileErr
 		}
-		// Check labels in live ns
-		labelsInLiveNs := namespace.GetLabels()
-		labelsToUpdate := namespaceHlp.MergeMaps(labelsInCrd, labelsInLiveNs)
-		// Enforces labels in ns
-		workingNs.SetLabels(labelsToUpdate)
-		if err = r.Update(ctx, workingNs); err != nil {
-			log.Log.Error(err, "Could not update labels "+
-				namespaceHlp.MapToStrings(labelsToUpdate)+
-				" for "+nsFullName)
+		// Every target applied the same objects, so any inventory will do
+		crdInstance.Status.BootstrapObjects = targets[0].instance.Status.BootstrapObjects
+		crdInstance.Status.Resources = targets[0].instance.Status.Resources
+		setReadyCondition(crdInstance, metav1.ConditionTrue, ricv1.ReasonReconciled,
+			"Namespaces "+strings.Join(nsNames, ", ")+" match the spec")
+		if err = r.updateStatus(ctx, crdInstance, originalStatus); err != nil {
 			return ctrl.Result{}, err
 		}
+		// Retired namespaces are checked again until they are empty
+		if len(crdInstance.Status.RetiredNamespaces) > 0 {
+			return ctrl.Result{RequeueAfter: retiredNamespaceRequeue}, nil
+		}
 	} else {
 		// CRD has a deletion timestamp. Clean up logic
-		workingNs.Name = nsFullName
-		if err := r.Delete(ctx, workingNs); err != nil {
-			log.Log.Error(err, "Namespace "+nsFullName+" could not be deleted")
-			return ctrl.Result{Requeue: false}, err
+		terminating, err := r.finalizeNamespaces(ctx, crdInstance, targets)
+		if err != nil {
+			log.Log.Error(err, "Could not apply the deletion policy of "+crdInstance.Name)
+			return ctrl.Result{}, err
+		}
+		// Keeps the finalizer until the namespaces are gone, so a CRD recreated
+		// with the same name does not race against them
+		if len(terminating) > 0 {
+			setTerminating(crdInstance, terminating)
+			r.diagnoseTermination(ctx, crdInstance, originalStatus.TerminationDiagnostics)
+			log.Log.Info("Waiting for namespace " + strings.Join(terminating, ", ") + " to be deleted since " + crdInstance.Status.TerminationElapsed)
+			if err := r.updateStatus(ctx, crdInstance, originalStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: namespaceTerminationRequeue}, nil
 		}
 		// Remove finalizer from CRD
 		crdInstance.Finalizers = namespaceHlp.RemoveString(crdInstance.Finalizers, crdFinalizer)
@@ -141,22 +313,356 @@ func (r *NamespaceConfigReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	return ctrl.Result{}, nil
 }
 
+// Creates or updates a namespace with the labels and annotations of the CRD
+// and enforces its contents
+func (r *NamespaceConfigReconciler) reconcileNamespace(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsFullName string) error {
+	var namespace corev1.Namespace
+	workingNs := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{},
+	}
+	labelsInCrd := desiredNamespaceLabels(crdInstance)
+	annotations, err := desiredNamespaceAnnotations(crdInstance)
+	if err != nil {
+		log.Log.Error(err, "Could not render annotations for "+nsFullName)
+		return err
+	}
+	workingNs.SetName(nsFullName)
+	workingNs.SetLabels(labelsInCrd)
+	workingNs.SetAnnotations(annotations)
+	err = r.Client.Get(ctx, types.NamespacedName{Name: nsFullName}, &namespace)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			log.Log.Error(err, "Error getting Namespace")
+			return err
+		}
+		log.Log.Info("Namespace " + nsFullName + " does not exists. Creating it")
+		// Create ns because it does not exists
+		if err = r.Create(ctx, workingNs); err != nil {
+			log.Log.Error(err, "Namespace could not be created: "+nsFullName)
+			return err
+		}
+		log.Log.Info("Namespace " + nsFullName + " created and labeled with " + namespaceHlp.MapToStrings(labelsInCrd))
+	} else {
+		// Check labels in live ns
+		labelsInLiveNs := namespace.GetLabels()
+		liveAnnotations := namespace.GetAnnotations()
+		// An unmanaged namespace being adopted keeps the labels it has now
+		foreignLabels := liveAnnotations[annForeignLabels]
+		if liveAnnotations[annOwnKey] != annOwnValue && len(labelsInLiveNs) > 0 {
+			foreignLabels = strings.Join(namespaceHlp.SortedKeys(labelsInLiveNs), ",")
+		}
+		labelsToUpdate := namespaceHlp.MergeLabels(labelsInCrd, labelsInLiveNs, strings.Split(foreignLabels, ","))
+		// Enforces labels in ns
+		workingNs.SetLabels(labelsToUpdate)
+		// Enforces annotations in ns while keeping foreign ones
+		previouslySet := strings.Split(liveAnnotations[annManagedKeys], ",")
+		annotationsToUpdate := namespaceHlp.MergeAnnotations(annotations, liveAnnotations, previouslySet)
+		if foreignLabels != "" {
+			annotationsToUpdate[annForeignLabels] = foreignLabels
+		}
+		workingNs.SetAnnotations(annotationsToUpdate)
+		if err = r.Update(ctx, workingNs); err != nil {
+			log.Log.Error(err, "Could not update labels "+
+				namespaceHlp.MapToStrings(labelsToUpdate)+
+				" for "+nsFullName)
+			return err
+		}
+	}
+	// Enforces the objects declared in the CRD inside the ns
+	return r.reconcileNamespaceContents(ctx, crdInstance, nsFullName)
+}
+
+// Reports in the status the namespace names that had to be truncated
+func setTruncatedCondition(crdInstance *ricv1.NamespaceConfig, targets []target) {
+	var truncated []string
+	for _, t := range targets {
+		if t.truncatedFrom != "" {
+			truncated = append(truncated, t.truncatedFrom+" truncated to "+t.name)
+		}
+	}
+	if len(truncated) == 0 {
+		meta.RemoveStatusCondition(&crdInstance.Status.Conditions, ricv1.ConditionNameTruncated)
+		return
+	}
+	meta.SetStatusCondition(&crdInstance.Status.Conditions, metav1.Condition{
+		Type:               ricv1.ConditionNameTruncated,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: crdInstance.Generation,
+		Reason:             ricv1.ReasonTooLong,
+		Message:            "Namespace name " + strings.Join(truncated, ", "),
+	})
+}
+
+// Writes the status of the CRD when the reconcile changed it
+func (r *NamespaceConfigReconciler) updateStatus(ctx context.Context, crdInstance *ricv1.NamespaceConfig, originalStatus *ricv1.NamespaceConfigStatus) error {
+	if equality.Semantic.DeepEqual(originalStatus, &crdInstance.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, crdInstance); err != nil {
+		log.Log.Error(err, "Could not update status of "+crdInstance.Name)
+		return err
+	}
+	return nil
+}
+
+// Generates the name of the namespace managed by the CRD for an environment,
+// or for the CRD itself when env is nil. Uses the name template when there is
+// one. Also returns the untruncated name when the CRD asked for truncation and
+// it was needed.
+func namespaceName(crdInstance *ricv1.NamespaceConfig, env *ricv1.Environment) (string, string, error) {
+	prefix := crdInstance.Spec.NamespacePrefix
+	envName, suffix := "", ""
+	if env != nil {
+		envName, suffix = env.Name, env.Suffix
+		if env.Prefix != "" {
+			prefix = env.Prefix
+		}
+	}
+	name := namespaceHlp.GenerateNamespaceName(crdInstance.Name, prefix)
+	if crdInstance.Spec.NameTemplate != "" {
+		rendered, err := namespaceHlp.RenderNamespaceName(crdInstance.Spec.NameTemplate, namespaceHlp.NameTemplateData{
+			Name:      crdInstance.Name,
+			Namespace: crdInstance.Namespace,
+			Prefix:    prefix,
+			Labels:    crdInstance.Labels,
+			Spec:      crdInstance.Spec,
+			Env:       envName,
+		})
+		if err != nil {
+			return "", "", err
+		}
+		name = rendered
+	}
+	name += suffix
+	truncatedFrom := ""
+	if crdInstance.Spec.TruncateName {
+		if truncated := namespaceHlp.TruncateNamespaceName(name); truncated != name {
+			truncatedFrom = name
+			name = truncated
+		}
+	}
+	if err := namespaceHlp.ValidateNamespaceName(name); err != nil {
+		return "", "", err
+	}
+	return name, truncatedFrom, nil
+}
+
+// Sets the Ready condition of the CRD
+func setReadyCondition(crdInstance *ricv1.NamespaceConfig, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&crdInstance.Status.Conditions, metav1.Condition{
+		Type:               ricv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: crdInstance.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// Publishes an event on the CRD when a recorder is configured
+func (r *NamespaceConfigReconciler) recordEvent(crdInstance *ricv1.NamespaceConfig, eventType string, reason string, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(crdInstance, eventType, reason, message)
+}
+
+// Checks the parts of the spec the CRD schema cannot validate
+func (r *NamespaceConfigReconciler) validateSpec(crdInstance *ricv1.NamespaceConfig) error {
+	return validateOwner(crdInstance.Spec.Owner, r.CostCenterPattern)
+}
+
+// Builds the labels the CRD enforces on the namespace. Typed fields take
+// precedence over raw labels.
+func desiredNamespaceLabels(crdInstance *ricv1.NamespaceConfig) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range crdInstance.Spec.Labels {
+		labels[key] = value
+	}
+	for key, value := range podSecurityLabels(crdInstance.Spec.PodSecurity) {
+		labels[key] = value
+	}
+	for key, value := range ownerLabels(crdInstance.Spec.Owner) {
+		labels[key] = value
+	}
+	return labels
+}
+
+// Builds the annotations the CRD enforces on the namespace. Typed fields take
+// precedence over raw annotations and the ownership annotation is always set.
+func desiredNamespaceAnnotations(crdInstance *ricv1.NamespaceConfig) (map[string]string, error) {
+	annotations := make(map[string]string)
+	for key, value := range crdInstance.Spec.Annotations {
+		annotations[key] = value
+	}
+	scheduling, err := schedulingAnnotations(crdInstance.Spec.Scheduling)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range scheduling {
+		annotations[key] = value
+	}
+	for key, value := range ownerAnnotations(crdInstance.Spec.Owner) {
+		annotations[key] = value
+	}
+	annotations[annOwnKey] = annOwnValue
+	annotations[annOwnerNamespace] = crdInstance.Namespace
+	annotations[annOwnerName] = crdInstance.Name
+	annotations[annOwnerUID] = string(crdInstance.UID)
+	annotations[annManagedKeys] = strings.Join(namespaceHlp.SortedKeys(annotations), ",")
+	return annotations, nil
+}
+
+// Enforces every object the CRD declares inside the managed namespace
+func (r *NamespaceConfigReconciler) reconcileNamespaceContents(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	if err := r.reconcileResourceQuota(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile ResourceQuota in "+nsName)
+		return err
+	}
+	if err := r.reconcileLimitRange(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile LimitRange in "+nsName)
+		return err
+	}
+	if err := r.reconcileNetworkPolicies(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile NetworkPolicies in "+nsName)
+		return err
+	}
+	if err := r.reconcileRoleBindings(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile RoleBindings in "+nsName)
+		return err
+	}
+	if err := r.reconcileServiceAccounts(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile ServiceAccounts in "+nsName)
+		return err
+	}
+	if err := r.reconcileSecrets(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile Secrets in "+nsName)
+		return err
+	}
+	if err := r.reconcileConfigMaps(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not reconcile ConfigMaps in "+nsName)
+		return err
+	}
+	// Objects may move between the bootstrap and spec.resources, so both
+	// inventories are pruned together once everything was applied
+	previous := appliedObjects(crdInstance)
+	if err := r.reconcileBootstrap(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not apply bootstrap manifests in "+nsName)
+		return err
+	}
+	if err := r.reconcileResources(ctx, crdInstance, nsName); err != nil {
+		log.Log.Error(err, "Could not apply resources in "+nsName)
+		return err
+	}
+	if err := r.pruneInventory(ctx, previous, appliedObjects(crdInstance), nsName); err != nil {
+		log.Log.Error(err, "Could not prune applied objects in "+nsName)
+		return err
+	}
+	return nil
+}
+
+// Lists the objects of both inventories of the CRD
+func appliedObjects(crdInstance *ricv1.NamespaceConfig) []ricv1.AppliedObject {
+	var objects []ricv1.AppliedObject
+	objects = append(objects, crdInstance.Status.BootstrapObjects...)
+	return append(objects, crdInstance.Status.Resources...)
+}
+
+// Checks if an object inside a managed namespace was created by the operator
+func isManaged(obj client.Object) bool {
+	return obj.GetLabels()[lblManagedKey] == lblManagedValue
+}
+
+// Marks an object inside a managed namespace as created by the operator
+func setManaged(obj client.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[lblManagedKey] = lblManagedValue
+	obj.SetLabels(labels)
+}
+
+// Deletes the objects of list created by the operator in nsName whose names
+// are not in keep
+func (r *NamespaceConfigReconciler) pruneManaged(ctx context.Context, list client.ObjectList, nsName string, keep map[string]bool) error {
+	if err := r.List(ctx, list, client.InNamespace(nsName), client.MatchingLabels{lblManagedKey: lblManagedValue}); err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok || keep[obj.GetName()] {
+			continue
+		}
+		gvk, _ := apiutil.GVKForObject(obj, r.Scheme)
+		log.Log.Info(gvk.Kind + " " + obj.GetName() + " no longer declared. Deleting it from " + nsName)
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Maps a managed namespace to the CRD that owns it, using the back-reference
+// annotations the CRD left on it
+func requestsForOwner(namespace client.Object) []reconcile.Request {
+	annotations := namespace.GetAnnotations()
+	if annotations[annOwnKey] != annOwnValue || annotations[annOwnerName] == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: annotations[annOwnerNamespace],
+		Name:      annotations[annOwnerName],
+	}}}
+}
+
+// Maps an object inside a managed namespace to the CRD owning the namespace
+func (r *NamespaceConfigReconciler) requestsForNamespace(ctx context.Context, nsName string) []reconcile.Request {
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: nsName}, &namespace); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Log.Error(err, "Could not get namespace "+nsName)
+		}
+		return nil
+	}
+	return requestsForOwner(&namespace)
+}
+
+// Maps an object created by the operator back to the CRD of its namespace so
+// manual edits get reverted
+func (r *NamespaceConfigReconciler) mapManagedObject(ctx context.Context, obj client.Object) []reconcile.Request {
+	if !isManaged(obj) {
+		return nil
+	}
+	return r.requestsForNamespace(ctx, obj.GetNamespace())
+}
+
 func (r *NamespaceConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ricv1.NamespaceConfig{}, secretSourceIndex, indexSecretSources); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ricv1.NamespaceConfig{}, configMapSourceIndex, indexConfigMapSources); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ricv1.NamespaceConfig{}, bootstrapSourceIndex, indexBootstrapSource); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &ricv1.NamespaceConfig{}, namespaceNameIndex, indexNamespaceNames); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ricv1.NamespaceConfig{}).
-		Watches(
-			&corev1.Namespace{},
-			handler.EnqueueRequestsFromMapFunc(
-				func(ctx context.Context, objectTriggeringReconcile client.Object) []reconcile.Request {
-					if objectTriggeringReconcile.GetAnnotations()[annOwnKey] == annOwnValue {
-						name := namespaceHlp.DeriveNamespaceConfigNameFromNamespace(objectTriggeringReconcile.GetName())
-						return []reconcile.Request{{NamespacedName: types.NamespacedName{
-							Namespace: "operator-ric",
-							Name:      name,
-						}}}
-					}
-					return nil
-				})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespace)).
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(r.mapManagedObject)).
+		Watches(&corev1.LimitRange{}, handler.EnqueueRequestsFromMapFunc(r.mapManagedObject)).
+		Watches(&networkingv1.NetworkPolicy{}, handler.EnqueueRequestsFromMapFunc(r.mapManagedObject)).
+		Watches(&rbacv1.RoleBinding{}, handler.EnqueueRequestsFromMapFunc(r.mapManagedObject)).
+		Watches(&corev1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(r.mapManagedObject)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mapSecret)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mapConfigMap)).
 		Complete(r)
 }
 
@@ -0,0 +1,361 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const (
+	specTimeout  = 10 * time.Second
+	specInterval = 250 * time.Millisecond
+	// Namespace the NamespaceConfigs of the specs live in
+	specNamespace = "default"
+)
+
+// Reads a NamespaceConfig of the specs
+func getNamespaceConfig(g Gomega, name string) *ricv1.NamespaceConfig {
+	crdInstance := &ricv1.NamespaceConfig{}
+	g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: specNamespace, Name: name}, crdInstance)).To(Succeed())
+	return crdInstance
+}
+
+// Reads a namespace
+func getNamespace(g Gomega, name string) *corev1.Namespace {
+	namespace := &corev1.Namespace{}
+	g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name}, namespace)).To(Succeed())
+	return namespace
+}
+
+// Edits the spec of a NamespaceConfig, retrying on conflicts
+func updateSpec(name string, edit func(spec *ricv1.NamespaceConfigSpec)) {
+	Eventually(func(g Gomega) {
+		crdInstance := getNamespaceConfig(g, name)
+		edit(&crdInstance.Spec)
+		g.Expect(k8sClient.Update(ctx, crdInstance)).To(Succeed())
+	}, specTimeout, specInterval).Should(Succeed())
+}
+
+// Deletes an object and clears its finalizers, as envtest runs no controller
+// that would
+func deleteNow(obj client.Object) {
+	Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, obj))).To(Succeed())
+	Eventually(func(g Gomega) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if errors.IsNotFound(err) {
+			return
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		obj.SetFinalizers(nil)
+		g.Expect(k8sClient.Update(ctx, obj)).To(Succeed())
+	}, specTimeout, specInterval).Should(Succeed())
+}
+
+// Builds a claim that keeps a namespace from being considered empty
+func busyClaim(nsName string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: nsName},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+var _ = Describe("NamespaceConfig name changes", func() {
+	It("stops retiring a namespace once a migrated rename is reverted", func() {
+		By("creating the namespace under its first name")
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "revert", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				NamespacePrefix:  "first-",
+				NameChangePolicy: ricv1.NameChangeMigrate,
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespaceConfig(g, "revert").Status.Namespace).To(Equal("first-revert"))
+		}, specTimeout, specInterval).Should(Succeed())
+
+		By("renaming it while the first namespace still holds a claim")
+		claim := busyClaim("first-revert")
+		Expect(k8sClient.Create(ctx, claim)).To(Succeed())
+		updateSpec("revert", func(spec *ricv1.NamespaceConfigSpec) { spec.NamespacePrefix = "second-" })
+		Eventually(func(g Gomega) {
+			status := getNamespaceConfig(g, "revert").Status
+			g.Expect(status.Namespace).To(Equal("second-revert"))
+			g.Expect(status.RetiredNamespaces).To(ConsistOf("first-revert"))
+		}, specTimeout, specInterval).Should(Succeed())
+
+		By("reverting the rename once the first namespace is empty")
+		deleteNow(claim)
+		updateSpec("revert", func(spec *ricv1.NamespaceConfigSpec) { spec.NamespacePrefix = "first-" })
+		Eventually(func(g Gomega) {
+			status := getNamespaceConfig(g, "revert").Status
+			g.Expect(status.Namespace).To(Equal("first-revert"))
+			g.Expect(status.RetiredNamespaces).NotTo(ContainElement("first-revert"))
+		}, specTimeout, specInterval).Should(Succeed())
+		Consistently(func(g Gomega) {
+			g.Expect(getNamespace(g, "first-revert").DeletionTimestamp).To(BeNil())
+		}, 3*time.Second, specInterval).Should(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceConfig environments", func() {
+	It("keeps the namespace of a renamed environment generating the same name", func() {
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "renamed-env", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				Environments: []ricv1.Environment{{Name: "dev", Prefix: "dev-"}},
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespaceConfig(g, "renamed-env").Status.Environments).To(ConsistOf(
+				HaveField("Namespace", "dev-renamed-env")))
+		}, specTimeout, specInterval).Should(Succeed())
+
+		updateSpec("renamed-env", func(spec *ricv1.NamespaceConfigSpec) { spec.Environments[0].Name = "development" })
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespaceConfig(g, "renamed-env").Status.Environments).To(ConsistOf(And(
+				HaveField("Name", "development"), HaveField("Namespace", "dev-renamed-env"))))
+		}, specTimeout, specInterval).Should(Succeed())
+		Consistently(func(g Gomega) {
+			g.Expect(getNamespace(g, "dev-renamed-env").DeletionTimestamp).To(BeNil())
+		}, 3*time.Second, specInterval).Should(Succeed())
+	})
+
+	It("keeps recording a removed environment until its namespace is deleted", func() {
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "removed-env", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				Environments: []ricv1.Environment{
+					{Name: "dev", Prefix: "dev-"},
+					{Name: "prod", Prefix: "prod-"},
+				},
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespaceConfig(g, "removed-env").Status.Environments).To(HaveLen(2))
+		}, specTimeout, specInterval).Should(Succeed())
+
+		// Without a namespace controller, the deleted namespace stays terminating
+		updateSpec("removed-env", func(spec *ricv1.NamespaceConfigSpec) { spec.Environments = spec.Environments[1:] })
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespace(g, "dev-removed-env").DeletionTimestamp).NotTo(BeNil())
+		}, specTimeout, specInterval).Should(Succeed())
+		recorded := func(g Gomega) {
+			g.Expect(getNamespaceConfig(g, "removed-env").Status.Environments).To(ConsistOf(
+				And(HaveField("Name", "prod"), HaveField("Ready", true)),
+				And(HaveField("Name", "dev"), HaveField("Namespace", "dev-removed-env"), HaveField("Ready", false)),
+			))
+		}
+		Eventually(recorded, specTimeout, specInterval).Should(Succeed())
+		Consistently(recorded, 3*time.Second, specInterval).Should(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceConfig Secret replication", func() {
+	It("copies a Secret outside the source namespaces only once it opts in", func() {
+		source := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: specNamespace},
+			StringData: map[string]string{"token": "secret"},
+		}
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "copy", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				NamespacePrefix: "secrets-",
+				Secrets:         []ricv1.SourceReference{{Name: "credentials", Namespace: specNamespace}},
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			ready := meta.FindStatusCondition(getNamespaceConfig(g, "copy").Status.Conditions, ricv1.ConditionReady)
+			g.Expect(ready).NotTo(BeNil())
+			g.Expect(ready.Reason).To(Equal(ricv1.ReasonSourceNotAllowed))
+		}, specTimeout, specInterval).Should(Succeed())
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: "secrets-copy", Name: "credentials"}, &corev1.Secret{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		source.SetAnnotations(map[string]string{annReplicable: "true"})
+		Expect(k8sClient.Update(ctx, source)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "secrets-copy", Name: "credentials"}, &corev1.Secret{})).To(Succeed())
+		}, specTimeout, specInterval).Should(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceConfig raw resources", func() {
+	It("reports kinds the cluster does not serve", func() {
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "widgets", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				Resources: []runtime.RawExtension{{
+					Raw: []byte(`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"gear"}}`),
+				}},
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			ready := meta.FindStatusCondition(getNamespaceConfig(g, "widgets").Status.Conditions, ricv1.ConditionReady)
+			g.Expect(ready).NotTo(BeNil())
+			g.Expect(ready.Reason).To(Equal(ricv1.ReasonUnsupportedResource))
+			g.Expect(ready.Message).To(ContainSubstring("Widget gear"))
+		}, specTimeout, specInterval).Should(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceConfig labels", func() {
+	It("reverts labels the spec does not declare", func() {
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				NamespacePrefix: "labels-",
+				Labels:          map[string]string{"team": "payments"},
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespace(g, "labels-strict").Labels).To(HaveKeyWithValue("team", "payments"))
+		}, specTimeout, specInterval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			namespace := getNamespace(g, "labels-strict")
+			namespace.Labels["manual"] = "true"
+			g.Expect(k8sClient.Update(ctx, namespace)).To(Succeed())
+		}, specTimeout, specInterval).Should(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespace(g, "labels-strict").Labels).NotTo(HaveKey("manual"))
+		}, specTimeout, specInterval).Should(Succeed())
+	})
+
+	It("keeps the labels an adopted namespace had", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "labels-adopted", Labels: map[string]string{"legacy": "true"}},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopted", Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				NamespacePrefix: "labels-",
+				AdoptionPolicy:  ricv1.AdoptionIfUnowned,
+				Labels:          map[string]string{"team": "payments"},
+			},
+		})).To(Succeed())
+		Eventually(func(g Gomega) {
+			labels := getNamespace(g, "labels-adopted").Labels
+			g.Expect(labels).To(HaveKeyWithValue("team", "payments"))
+			g.Expect(labels).To(HaveKeyWithValue("legacy", "true"))
+		}, specTimeout, specInterval).Should(Succeed())
+		Consistently(func(g Gomega) {
+			g.Expect(getNamespace(g, "labels-adopted").Labels).To(HaveKey("legacy"))
+		}, 3*time.Second, specInterval).Should(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceConfig deletion policy", func() {
+	// Creates a NamespaceConfig, waits for its namespace and deletes it
+	createAndDelete := func(name string, policy ricv1.DeletionPolicy) {
+		crdInstance := &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: specNamespace},
+			Spec: ricv1.NamespaceConfigSpec{
+				NamespacePrefix: "deletion-",
+				DeletionPolicy:  policy,
+			},
+		}
+		Expect(k8sClient.Create(ctx, crdInstance)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespaceConfig(g, name).Status.Namespace).To(Equal("deletion-" + name))
+		}, specTimeout, specInterval).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, crdInstance)).To(Succeed())
+	}
+	// Waits for a NamespaceConfig to be gone
+	expectGone := func(name string) {
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, types.NamespacedName{Namespace: specNamespace, Name: name}, &ricv1.NamespaceConfig{})
+			g.Expect(errors.IsNotFound(err)).To(BeTrue())
+		}, specTimeout, specInterval).Should(Succeed())
+	}
+
+	It("releases the namespace with Retain", func() {
+		createAndDelete("retain", ricv1.DeletionRetain)
+		expectGone("retain")
+		namespace := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "deletion-retain"}, namespace)).To(Succeed())
+		Expect(namespace.DeletionTimestamp).To(BeNil())
+		Expect(namespace.Annotations).NotTo(HaveKey(annOwnKey))
+		Expect(namespace.Annotations).NotTo(HaveKey(annOwnerUID))
+	})
+
+	It("leaves the namespace as it is with Orphan", func() {
+		createAndDelete("orphan", ricv1.DeletionOrphan)
+		expectGone("orphan")
+		namespace := &corev1.Namespace{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "deletion-orphan"}, namespace)).To(Succeed())
+		Expect(namespace.DeletionTimestamp).To(BeNil())
+		Expect(namespace.Annotations).To(HaveKeyWithValue(annOwnKey, annOwnValue))
+	})
+
+	It("waits for the namespace to be deleted with Delete", func() {
+		createAndDelete("delete", ricv1.DeletionDelete)
+		// Without a namespace controller, the namespace stays terminating
+		Eventually(func(g Gomega) {
+			g.Expect(getNamespace(g, "deletion-delete").DeletionTimestamp).NotTo(BeNil())
+			status := getNamespaceConfig(g, "delete").Status
+			g.Expect(status.Phase).To(Equal(ricv1.PhaseTerminating))
+			g.Expect(status.TerminatingNamespaces).To(ConsistOf("deletion-delete"))
+		}, specTimeout, specInterval).Should(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceConfig conflicts", func() {
+	// Waits for the Conflict condition of a NamespaceConfig
+	expectConflict := func(name string) {
+		Eventually(func(g Gomega) {
+			conflict := meta.FindStatusCondition(getNamespaceConfig(g, name).Status.Conditions, ricv1.ConditionConflict)
+			g.Expect(conflict).NotTo(BeNil())
+			g.Expect(conflict.Reason).To(Equal(ricv1.ReasonNamespaceConflict))
+		}, specTimeout, specInterval).Should(Succeed())
+	}
+
+	It("leaves an existing unmanaged namespace alone", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "conflict-existing"},
+		})).To(Succeed())
+		Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: specNamespace},
+			Spec:       ricv1.NamespaceConfigSpec{NamespacePrefix: "conflict-"},
+		})).To(Succeed())
+		expectConflict("existing")
+		Consistently(func(g Gomega) {
+			g.Expect(getNamespace(g, "conflict-existing").Annotations).NotTo(HaveKey(annOwnKey))
+		}, 3*time.Second, specInterval).Should(Succeed())
+	})
+
+	It("lets the first NamespaceConfig generating a name keep it", func() {
+		for _, name := range []string{"first", "second"} {
+			Expect(k8sClient.Create(ctx, &ricv1.NamespaceConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: specNamespace},
+				Spec:       ricv1.NamespaceConfigSpec{NameTemplate: "conflict-shared"},
+			})).To(Succeed())
+			if name == "first" {
+				Eventually(func(g Gomega) {
+					g.Expect(getNamespace(g, "conflict-shared").Annotations).To(HaveKeyWithValue(annOwnerName, "first"))
+				}, specTimeout, specInterval).Should(Succeed())
+			}
+		}
+		expectConflict("second")
+		Consistently(func(g Gomega) {
+			g.Expect(getNamespace(g, "conflict-shared").Annotations).To(HaveKeyWithValue(annOwnerName, "first"))
+		}, 3*time.Second, specInterval).Should(Succeed())
+	})
+})
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const (
+	netpolDefaultDenyName   string = "ns-operator-default-deny"
+	netpolSameNamespaceName string = "ns-operator-allow-same-namespace"
+	netpolAllowedPeersName  string = "ns-operator-allow-peers"
+)
+
+// Creates or updates the NetworkPolicies of the selected profile and prunes
+// the managed ones the profile no longer needs
+func (r *NamespaceConfigReconciler) reconcileNetworkPolicies(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	desired, err := renderNetworkPolicies(crdInstance.Spec.NetworkPolicy)
+	if err != nil {
+		return err
+	}
+	for name, spec := range desired {
+		policy := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: nsName,
+			},
+		}
+		spec := spec
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+			setManaged(policy)
+			policy.Spec = spec
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Log.Info("NetworkPolicy " + name + " in " + nsName + " " + string(op))
+		}
+	}
+	// Prune the policies left behind by a previous profile
+	keep := make(map[string]bool)
+	for name := range desired {
+		keep[name] = true
+	}
+	return r.pruneManaged(ctx, &networkingv1.NetworkPolicyList{}, nsName, keep)
+}
+
+// Builds the NetworkPolicies of a profile, keyed by name
+func renderNetworkPolicies(spec *ricv1.NetworkPolicySpec) (map[string]networkingv1.NetworkPolicySpec, error) {
+	policies := make(map[string]networkingv1.NetworkPolicySpec)
+	if spec == nil || spec.Profile == "" || spec.Profile == ricv1.NetworkProfileNone {
+		return policies, nil
+	}
+	allTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	policies[netpolDefaultDenyName] = networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: allTypes,
+	}
+	if spec.Profile == ricv1.NetworkProfileSameNamespaceOnly {
+		sameNamespace := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+		udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+		dnsPort := intstr.FromInt(53)
+		policies[netpolSameNamespaceName] = networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: allTypes,
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: sameNamespace}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{To: sameNamespace},
+				{Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dnsPort},
+					{Protocol: &tcp, Port: &dnsPort},
+				}},
+			},
+		}
+	}
+	var peers []networkingv1.NetworkPolicyPeer
+	for i := range spec.AllowedNamespaces {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: spec.AllowedNamespaces[i].DeepCopy()})
+	}
+	var egress []networkingv1.NetworkPolicyEgressRule
+	if len(peers) > 0 {
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{To: peers})
+	}
+	for _, cidr := range spec.AllowedEgressCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid egress CIDR %q: %w", cidr, err)
+		}
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: cidr}}},
+		})
+	}
+	if len(peers) > 0 || len(egress) > 0 {
+		allowed := networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: allTypes,
+			Egress:      egress,
+		}
+		if len(peers) > 0 {
+			allowed.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: peers}}
+		}
+		policies[netpolAllowedPeersName] = allowed
+	}
+	return policies, nil
+}
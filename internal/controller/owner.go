@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const (
+	lblOwnerName       string = "owner.ric.com/name"
+	lblOwnerTeam       string = "owner.ric.com/team"
+	lblOwnerCostCenter string = "owner.ric.com/cost-center"
+	annOwnerContact    string = "owner.ric.com/contact"
+)
+
+// Checks the owner block beyond what the CRD schema can express
+func validateOwner(spec *ricv1.OwnerSpec, costCenterPattern *regexp.Regexp) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.Contact != "" {
+		address, err := mail.ParseAddress(spec.Contact)
+		if err != nil || address.Address != spec.Contact {
+			return fmt.Errorf("owner contact %q is not a valid email address", spec.Contact)
+		}
+	}
+	if costCenterPattern != nil && spec.CostCenter != "" && !costCenterPattern.MatchString(spec.CostCenter) {
+		return fmt.Errorf("owner cost center %q does not match %s", spec.CostCenter, costCenterPattern.String())
+	}
+	return nil
+}
+
+// Renders the owner block as namespace labels
+func ownerLabels(spec *ricv1.OwnerSpec) map[string]string {
+	labels := make(map[string]string)
+	if spec == nil {
+		return labels
+	}
+	labels[lblOwnerName] = spec.Name
+	labels[lblOwnerTeam] = spec.Team
+	if spec.CostCenter != "" {
+		labels[lblOwnerCostCenter] = spec.CostCenter
+	}
+	return labels
+}
+
+// Renders the parts of the owner block that are not valid label values as
+// namespace annotations
+func ownerAnnotations(spec *ricv1.OwnerSpec) map[string]string {
+	annotations := make(map[string]string)
+	if spec == nil {
+		return annotations
+	}
+	if spec.Contact != "" {
+		annotations[annOwnerContact] = spec.Contact
+	}
+	return annotations
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const podSecurityLabelPrefix string = "pod-security.kubernetes.io/"
+
+// Renders the Pod Security Admission labels of the CRD
+func podSecurityLabels(spec *ricv1.PodSecuritySpec) map[string]string {
+	labels := make(map[string]string)
+	if spec == nil {
+		return labels
+	}
+	modes := []struct {
+		mode    string
+		level   ricv1.PodSecurityLevel
+		version string
+	}{
+		{"enforce", spec.Enforce, spec.EnforceVersion},
+		{"audit", spec.Audit, spec.AuditVersion},
+		{"warn", spec.Warn, spec.WarnVersion},
+	}
+	for _, m := range modes {
+		if m.level != "" {
+			labels[podSecurityLabelPrefix+m.mode] = string(m.level)
+		}
+		if m.version != "" {
+			labels[podSecurityLabelPrefix+m.mode+"-version"] = m.version
+		}
+	}
+	return labels
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
+)
+
+// Checks if the operator must never create, update nor delete a namespace
+func (r *NamespaceConfigReconciler) isProtected(nsName string) bool {
+	return namespaceHlp.MatchesPattern(nsName, r.ProtectedNamespaces)
+}
+
+// Describes the targets matching the protected namespace patterns. Returns an
+// empty string when none does.
+func protectedTargets(targets []target, patterns []string) string {
+	var protected []string
+	for _, t := range targets {
+		if namespaceHlp.MatchesPattern(t.name, patterns) {
+			protected = append(protected, t.name)
+		}
+	}
+	if len(protected) == 0 {
+		return ""
+	}
+	return "Namespace " + strings.Join(protected, ", ") + " is protected and cannot be managed by the operator"
+}
+
+// NamespaceConfigValidator rejects CRDs whose namespace names cannot be
+// generated or are protected
+type NamespaceConfigValidator struct {
+	// ProtectedNamespaces holds the exact names and glob patterns of the
+	// namespaces the operator must never manage
+	ProtectedNamespaces []string
+}
+
+//+kubebuilder:webhook:path=/validate-ric-ric-com-v1-namespaceconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=ric.ric.com,resources=namespaceconfigs,verbs=create;update,versions=v1,name=vnamespaceconfig.ric.com,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the validating webhook of the CRD
+func (v *NamespaceConfigValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&ricv1.NamespaceConfig{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate rejects CRDs generating an invalid or protected namespace
+func (v *NamespaceConfigValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate rejects updates making the CRD generate an invalid or
+// protected namespace
+func (v *NamespaceConfigValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete always allows the deletion. The reconciler leaves protected
+// namespaces alone.
+func (v *NamespaceConfigValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NamespaceConfigValidator) validate(obj runtime.Object) error {
+	crdInstance, ok := obj.(*ricv1.NamespaceConfig)
+	if !ok {
+		return fmt.Errorf("expected a NamespaceConfig but got %T", obj)
+	}
+	// The finalizer of a CRD being deleted must be removable
+	if !crdInstance.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	targets, err := namespaceTargets(crdInstance)
+	if err != nil {
+		return err
+	}
+	if protected := protectedTargets(targets, v.ProtectedNamespaces); protected != "" {
+		return fmt.Errorf("%s", protected)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
+)
+
+const (
+	// Annotation on a copy pointing to the object it was copied from
+	annSourceKey string = "ric.com/replicated-from"
+	// Annotation a source outside the source namespaces of the operator
+	// carries to be copied
+	annReplicable string = "ric.com/replicable"
+)
+
+// Reads an object a CRD copies from. Objects outside the source namespaces of
+// the operator are only read when they opt in through the replicable
+// annotation, so a CRD cannot copy any Secret of the cluster. Missing objects
+// outside the source namespaces are rejected as well, so their existence does
+// not leak.
+func (r *NamespaceConfigReconciler) getSource(ctx context.Context, kind string, namespace string, name string, source client.Object) error {
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, source)
+	if namespaceHlp.MatchesPattern(namespace, r.SourceNamespaces) {
+		return err
+	}
+	if err == nil && source.GetAnnotations()[annReplicable] == "true" {
+		return nil
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return &rejectedError{
+		reason: ricv1.ReasonSourceNotAllowed,
+		message: kind + " " + sourceKey(namespace, name) + " may not be copied. Its namespace is not a source namespace " +
+			"of the operator and it lacks the " + annReplicable + "=true annotation",
+	}
+}
+
+// Lists the CRDs whose index holds the namespace/name of obj
+func (r *NamespaceConfigReconciler) requestsForSource(ctx context.Context, index string, obj client.Object) []reconcile.Request {
+	var crdList ricv1.NamespaceConfigList
+	if err := r.List(ctx, &crdList, client.MatchingFields{index: sourceKey(obj.GetNamespace(), obj.GetName())}); err != nil {
+		log.Log.Error(err, "Could not list CRDs referencing "+sourceKey(obj.GetNamespace(), obj.GetName()))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(crdList.Items))
+	for _, crdInstance := range crdList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: crdInstance.Namespace,
+			Name:      crdInstance.Name,
+		}})
+	}
+	return requests
+}
+
+// Name given to the copy of a referenced object
+func replicaName(ref ricv1.SourceReference) string {
+	if ref.TargetName != "" {
+		return ref.TargetName
+	}
+	return ref.Name
+}
+
+// Records on a copy the object it was copied from
+func setSource(copied client.Object, source client.Object) {
+	annotations := copied.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[annSourceKey] = sourceKey(source.GetNamespace(), source.GetName())
+	copied.SetAnnotations(annotations)
+}
+
+func sourceKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const resourceQuotaName string = "ns-operator-quota"
+
+// Creates or updates the ResourceQuota declared in the CRD. Deletes it when
+// the CRD no longer declares one.
+func (r *NamespaceConfigReconciler) reconcileResourceQuota(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceQuotaName,
+			Namespace: nsName,
+		},
+	}
+	if crdInstance.Spec.ResourceQuota == nil {
+		err := r.Get(ctx, types.NamespacedName{Namespace: nsName, Name: resourceQuotaName}, quota)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !isManaged(quota) {
+			return nil
+		}
+		log.Log.Info("ResourceQuota removed from spec. Deleting it from " + nsName)
+		if err := r.Delete(ctx, quota); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		setManaged(quota)
+		quota.Spec = *crdInstance.Spec.ResourceQuota.DeepCopy()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Log.Info("ResourceQuota " + resourceQuotaName + " in " + nsName + " " + string(op))
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Applies the raw objects of spec.resources into the namespace and records
+// what was applied in the status. Objects removed from the list are pruned by
+// reconcileNamespaceContents.
+func (r *NamespaceConfigReconciler) reconcileResources(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	var applied []ricv1.AppliedObject
+	for i, raw := range crdInstance.Spec.Resources {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			return fmt.Errorf("could not decode spec.resources[%d]: %w", i, err)
+		}
+		entry, err := r.applyObject(ctx, obj, nsName)
+		if err != nil {
+			return err
+		}
+		applied = append(applied, entry)
+	}
+	crdInstance.Status.Resources = applied
+	return nil
+}
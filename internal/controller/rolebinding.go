@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const roleBindingPrefix string = "ns-operator-"
+
+// Creates or updates one RoleBinding per access entry in the CRD and deletes
+// the managed ones whose entry was removed
+func (r *NamespaceConfigReconciler) reconcileRoleBindings(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	keep := make(map[string]bool)
+	for _, access := range crdInstance.Spec.Access {
+		name := roleBindingPrefix + access.ClusterRole
+		keep[name] = true
+		binding := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: nsName,
+			},
+		}
+		subjects := renderSubjects(access, nsName)
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, binding, func() error {
+			setManaged(binding)
+			// RoleRef is immutable. The name is derived from it, so an update
+			// never changes it
+			binding.RoleRef = rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     access.ClusterRole,
+			}
+			binding.Subjects = subjects
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Log.Info("RoleBinding " + name + " in " + nsName + " " + string(op))
+		}
+	}
+	return r.pruneManaged(ctx, &rbacv1.RoleBindingList{}, nsName, keep)
+}
+
+// Translates the users, groups and ServiceAccounts of an access entry into
+// RoleBinding subjects
+func renderSubjects(access ricv1.AccessBinding, nsName string) []rbacv1.Subject {
+	var subjects []rbacv1.Subject
+	for _, user := range access.Users {
+		subjects = append(subjects, rbacv1.Subject{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.UserKind,
+			Name:     user,
+		})
+	}
+	for _, group := range access.Groups {
+		subjects = append(subjects, rbacv1.Subject{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.GroupKind,
+			Name:     group,
+		})
+	}
+	for _, sa := range access.ServiceAccounts {
+		namespace := sa.Namespace
+		if namespace == "" {
+			namespace = nsName
+		}
+		subjects = append(subjects, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      sa.Name,
+			Namespace: namespace,
+		})
+	}
+	return subjects
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+const (
+	annNodeSelector       string = "scheduler.alpha.kubernetes.io/node-selector"
+	annDefaultTolerations string = "scheduler.alpha.kubernetes.io/defaultTolerations"
+)
+
+// Renders the scheduling constraints of the CRD as the namespace annotations
+// read by the PodNodeSelector and PodTolerationRestriction admission plugins
+func schedulingAnnotations(spec *ricv1.SchedulingSpec) (map[string]string, error) {
+	annotations := make(map[string]string)
+	if spec == nil {
+		return annotations, nil
+	}
+	if len(spec.NodeSelector) > 0 {
+		// Sorted so the annotation does not change between reconciles
+		selector := make([]string, 0, len(spec.NodeSelector))
+		for key, value := range spec.NodeSelector {
+			selector = append(selector, key+"="+value)
+		}
+		sort.Strings(selector)
+		annotations[annNodeSelector] = strings.Join(selector, ",")
+	}
+	if len(spec.DefaultTolerations) > 0 {
+		tolerations, err := json.Marshal(spec.DefaultTolerations)
+		if err != nil {
+			return nil, err
+		}
+		annotations[annDefaultTolerations] = string(tolerations)
+	}
+	return annotations, nil
+}
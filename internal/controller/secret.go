@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+)
+
+// Field index on the namespace/name of the Secrets a CRD references
+const secretSourceIndex string = "spec.secrets.source"
+
+// Copies the Secrets referenced in the CRD into the namespace and deletes the
+// copies whose reference was removed
+func (r *NamespaceConfigReconciler) reconcileSecrets(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	keep := make(map[string]bool)
+	for _, ref := range crdInstance.Spec.Secrets {
+		targetName := replicaName(ref)
+		keep[targetName] = true
+		var source corev1.Secret
+		err := r.getSource(ctx, "Secret", ref.Namespace, ref.Name, &source)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				// The watch on Secrets brings us back once it shows up
+				log.Log.Info("Source Secret " + sourceKey(ref.Namespace, ref.Name) + " not found. Skipping copy into " + nsName)
+				continue
+			}
+			return err
+		}
+		if err := r.applySecretCopy(ctx, &source, targetName, nsName); err != nil {
+			return err
+		}
+	}
+	return r.pruneManaged(ctx, &corev1.SecretList{}, nsName, keep)
+}
+
+// Creates or updates the copy of a source Secret
+func (r *NamespaceConfigReconciler) applySecretCopy(ctx context.Context, source *corev1.Secret, targetName string, nsName string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      targetName,
+			Namespace: nsName,
+		},
+	}
+	// The type of a Secret is immutable, so a copy with another type has to
+	// be recreated
+	err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	if err == nil && secret.Type != source.Type && isManaged(secret) {
+		log.Log.Info("Secret " + targetName + " in " + nsName + " changed type. Recreating it")
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      targetName,
+				Namespace: nsName,
+			},
+		}
+	} else if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		setManaged(secret)
+		setSource(secret, source)
+		secret.Type = source.Type
+		secret.Data = source.DeepCopy().Data
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Log.Info("Secret " + targetName + " in " + nsName + " " + string(op) + " from " + sourceKey(source.Namespace, source.Name))
+	}
+	return nil
+}
+
+// Indexes a CRD by the namespace/name of every Secret it references
+func indexSecretSources(obj client.Object) []string {
+	crdInstance, ok := obj.(*ricv1.NamespaceConfig)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, ref := range crdInstance.Spec.Secrets {
+		keys = append(keys, sourceKey(ref.Namespace, ref.Name))
+	}
+	return keys
+}
+
+// Maps a Secret to the CRDs that need to reconcile it. Copies route to the
+// CRD of their namespace and sources fan out to every CRD referencing them.
+func (r *NamespaceConfigReconciler) mapSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	if isManaged(obj) {
+		return r.requestsForNamespace(ctx, obj.GetNamespace())
+	}
+	return r.requestsForSource(ctx, secretSourceIndex, obj)
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
+)
+
+const defaultServiceAccount string = "default"
+
+// Creates or updates the ServiceAccounts declared in the CRD, hardens the
+// default one if asked to and deletes the managed ones removed from the spec
+func (r *NamespaceConfigReconciler) reconcileServiceAccounts(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) error {
+	// The default ServiceAccount belongs to Kubernetes and is never pruned
+	keep := map[string]bool{defaultServiceAccount: true}
+	var items []ricv1.ServiceAccountSpec
+	hardenDefault := false
+	if crdInstance.Spec.ServiceAccounts != nil {
+		items = crdInstance.Spec.ServiceAccounts.Items
+		hardenDefault = crdInstance.Spec.ServiceAccounts.HardenDefault
+	}
+	declaresDefault := false
+	for i := range items {
+		desired := items[i]
+		keep[desired.Name] = true
+		if desired.Name == defaultServiceAccount {
+			declaresDefault = true
+		}
+		if err := r.applyServiceAccount(ctx, nsName, desired, hardenDefault); err != nil {
+			return err
+		}
+	}
+	if hardenDefault && !declaresDefault {
+		desired := ricv1.ServiceAccountSpec{Name: defaultServiceAccount}
+		if err := r.applyServiceAccount(ctx, nsName, desired, hardenDefault); err != nil {
+			return err
+		}
+	} else if !declaresDefault {
+		if err := r.restoreDefaultServiceAccount(ctx, nsName); err != nil {
+			return err
+		}
+	}
+	return r.pruneManaged(ctx, &corev1.ServiceAccountList{}, nsName, keep)
+}
+
+// Creates or updates a single ServiceAccount. Annotations are merged so the
+// ones added by other tools are kept, and the ones removed from the spec are
+// dropped.
+func (r *NamespaceConfigReconciler) applyServiceAccount(ctx context.Context, nsName string, desired ricv1.ServiceAccountSpec, hardenDefault bool) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      desired.Name,
+			Namespace: nsName,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+		setManaged(sa)
+		previouslySet := strings.Split(sa.GetAnnotations()[annManagedKeys], ",")
+		annotations := namespaceHlp.MergeAnnotations(desired.Annotations, sa.GetAnnotations(), previouslySet)
+		delete(annotations, annManagedKeys)
+		if len(desired.Annotations) > 0 {
+			annotations[annManagedKeys] = strings.Join(namespaceHlp.SortedKeys(desired.Annotations), ",")
+		}
+		sa.SetAnnotations(annotations)
+		var pullSecrets []corev1.LocalObjectReference
+		for _, secret := range desired.ImagePullSecrets {
+			pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+		}
+		sa.ImagePullSecrets = pullSecrets
+		sa.AutomountServiceAccountToken = desired.AutomountServiceAccountToken
+		if desired.Name == defaultServiceAccount && hardenDefault {
+			automount := false
+			sa.AutomountServiceAccountToken = &automount
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		log.Log.Info("ServiceAccount " + desired.Name + " in " + nsName + " " + string(op))
+	}
+	return nil
+}
+
+// Hands the default ServiceAccount back to Kubernetes once the CRD no longer
+// hardens nor declares it: drops the managed label, the annotations the
+// operator set and the automount setting
+func (r *NamespaceConfigReconciler) restoreDefaultServiceAccount(ctx context.Context, nsName string) error {
+	var sa corev1.ServiceAccount
+	if err := r.Get(ctx, types.NamespacedName{Namespace: nsName, Name: defaultServiceAccount}, &sa); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !isManaged(&sa) {
+		return nil
+	}
+	patch := client.MergeFrom(sa.DeepCopy())
+	labels := sa.GetLabels()
+	delete(labels, lblManagedKey)
+	sa.SetLabels(labels)
+	annotations := sa.GetAnnotations()
+	for _, key := range strings.Split(annotations[annManagedKeys], ",") {
+		delete(annotations, key)
+	}
+	delete(annotations, annManagedKeys)
+	sa.SetAnnotations(annotations)
+	sa.ImagePullSecrets = nil
+	sa.AutomountServiceAccountToken = nil
+	if err := r.Patch(ctx, &sa, patch); err != nil {
+		return err
+	}
+	log.Log.Info("ServiceAccount " + defaultServiceAccount + " in " + nsName + " restored")
+	return nil
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ricv1 "github.com/RicHincapie/ns-operator/api/v1"
+	namespaceHlp "github.com/RicHincapie/ns-operator/pkg/namespace"
+)
+
+// Namespaces terminating for longer than this are diagnosed, at most once
+// per period
+const terminationDiagnosisDelay = time.Minute
+
+// Resources whose finalizers may be stripped to unblock a terminating
+// namespace. Their finalizers guard nothing outside the namespace.
+var safeFinalizerResources = map[schema.GroupResource]bool{
+	{Resource: "configmaps"}:                                       true,
+	{Resource: "secrets"}:                                          true,
+	{Resource: "serviceaccounts"}:                                  true,
+	{Group: "rbac.authorization.k8s.io", Resource: "roles"}:        true,
+	{Group: "rbac.authorization.k8s.io", Resource: "rolebindings"}: true,
+}
+
+// Inspects the namespaces a deleted CRD has been waiting on for too long,
+// reports what blocks them in the status and in events, and strips the
+// finalizers of known-safe kinds when enabled. Between two passes only the
+// diagnostics of the namespaces still terminating are kept.
+func (r *NamespaceConfigReconciler) diagnoseTermination(ctx context.Context, crdInstance *ricv1.NamespaceConfig, previous []ricv1.TerminationDiagnostic) {
+	if time.Since(crdInstance.Status.TerminationStartedAt.Time) < terminationDiagnosisDelay {
+		return
+	}
+	if diagnosedAt := crdInstance.Status.TerminationDiagnosedAt; diagnosedAt != nil && time.Since(diagnosedAt.Time) < terminationDiagnosisDelay {
+		var kept []ricv1.TerminationDiagnostic
+		for _, diagnostic := range previous {
+			if namespaceHlp.ContainsString(crdInstance.Status.TerminatingNamespaces, diagnostic.Namespace) {
+				kept = append(kept, diagnostic)
+			}
+		}
+		crdInstance.Status.TerminationDiagnostics = kept
+		return
+	}
+	// Resources installed since the last pass are listed as well
+	if cached, ok := r.Discovery.(discovery.CachedDiscoveryInterface); ok {
+		cached.Invalidate()
+	}
+	now := metav1.Now()
+	crdInstance.Status.TerminationDiagnosedAt = &now
+	reported := make(map[string]ricv1.TerminationDiagnostic)
+	for _, diagnostic := range previous {
+		reported[diagnostic.Namespace] = diagnostic
+	}
+	var diagnostics []ricv1.TerminationDiagnostic
+	for _, nsName := range crdInstance.Status.TerminatingNamespaces {
+		diagnostic, err := r.diagnoseNamespace(ctx, crdInstance, nsName)
+		if err != nil {
+			log.Log.Error(err, "Could not diagnose terminating namespace "+nsName)
+		}
+		diagnostics = append(diagnostics, diagnostic)
+		// Only changes are published, not every requeue
+		if before, exists := reported[nsName]; !exists || !equality.Semantic.DeepEqual(before, diagnostic) {
+			message := summarizeDiagnostic(diagnostic, crdInstance.Status.TerminationElapsed)
+			log.Log.Info(message)
+			r.recordEvent(crdInstance, corev1.EventTypeWarning, ricv1.ReasonNamespaceStuck, message)
+		}
+	}
+	crdInstance.Status.TerminationDiagnostics = diagnostics
+}
+
+// Reads the conditions of a terminating namespace and lists the objects left
+// in it through discovery
+func (r *NamespaceConfigReconciler) diagnoseNamespace(ctx context.Context, crdInstance *ricv1.NamespaceConfig, nsName string) (ricv1.TerminationDiagnostic, error) {
+	diagnostic := ricv1.TerminationDiagnostic{Namespace: nsName}
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: nsName}, &namespace); err != nil {
+		return diagnostic, client.IgnoreNotFound(err)
+	}
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			diagnostic.Conditions = append(diagnostic.Conditions, string(condition.Type)+": "+condition.Message)
+		}
+	}
+	if r.Discovery == nil {
+		return diagnostic, nil
+	}
+	resourceLists, err := r.Discovery.ServerPreferredNamespacedResources()
+	// Groups failing discovery are skipped, the others are still listed
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return diagnostic, err
+	}
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") || !namespaceHlp.ContainsString(resource.Verbs, "list") {
+				continue
+			}
+			gr := schema.GroupResource{Group: gv.Group, Resource: resource.Name}
+			objects := &metav1.PartialObjectMetadataList{}
+			objects.SetGroupVersionKind(gv.WithKind(resource.Kind + "List"))
+			if err := reader.List(ctx, objects, client.InNamespace(nsName)); err != nil {
+				log.Log.Error(err, "Could not list "+gr.String()+" in "+nsName)
+				continue
+			}
+			if len(objects.Items) == 0 {
+				continue
+			}
+			remaining := ricv1.RemainingResource{Resource: gr.String(), Count: len(objects.Items)}
+			finalizers := make(map[string]bool)
+			for i := range objects.Items {
+				for _, finalizer := range objects.Items[i].GetFinalizers() {
+					finalizers[finalizer] = true
+				}
+			}
+			for finalizer := range finalizers {
+				remaining.Finalizers = append(remaining.Finalizers, finalizer)
+			}
+			sort.Strings(remaining.Finalizers)
+			diagnostic.Remaining = append(diagnostic.Remaining, remaining)
+			if r.StripSafeFinalizers && safeFinalizerResources[gr] && len(finalizers) > 0 {
+				if err := r.stripFinalizers(ctx, crdInstance, gv.WithKind(resource.Kind), gr, objects.Items); err != nil {
+					log.Log.Error(err, "Could not strip the finalizers of "+gr.String()+" in "+nsName)
+				}
+			}
+		}
+	}
+	sort.Slice(diagnostic.Remaining, func(i, j int) bool {
+		return diagnostic.Remaining[i].Resource < diagnostic.Remaining[j].Resource
+	})
+	return diagnostic, nil
+}
+
+// Removes the finalizers of objects of a known-safe kind left in a
+// terminating namespace
+func (r *NamespaceConfigReconciler) stripFinalizers(ctx context.Context, crdInstance *ricv1.NamespaceConfig, gvk schema.GroupVersionKind, gr schema.GroupResource, items []metav1.PartialObjectMetadata) error {
+	for i := range items {
+		item := &items[i]
+		if len(item.GetFinalizers()) == 0 {
+			continue
+		}
+		item.SetGroupVersionKind(gvk)
+		patch := client.MergeFrom(item.DeepCopy())
+		message := fmt.Sprintf("Stripped finalizers %s from %s %s in terminating namespace %s",
+			strings.Join(item.GetFinalizers(), ", "), gr.String(), item.GetName(), item.GetNamespace())
+		item.SetFinalizers(nil)
+		if err := r.Patch(ctx, item, patch); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		log.Log.Info(message)
+		r.recordEvent(crdInstance, corev1.EventTypeNormal, ricv1.ReasonFinalizersStripped, message)
+	}
+	return nil
+}
+
+// Describes in one line what blocks a terminating namespace
+func summarizeDiagnostic(diagnostic ricv1.TerminationDiagnostic, elapsed string) string {
+	message := "Namespace " + diagnostic.Namespace + " terminating for " + elapsed
+	if len(diagnostic.Conditions) > 0 {
+		message += ". " + strings.Join(diagnostic.Conditions, ". ")
+	}
+	if len(diagnostic.Remaining) == 0 {
+		return message
+	}
+	var remaining []string
+	for _, resource := range diagnostic.Remaining {
+		described := fmt.Sprintf("%s (%d", resource.Resource, resource.Count)
+		if len(resource.Finalizers) > 0 {
+			described += ", finalizers " + strings.Join(resource.Finalizers, ", ")
+		}
+		remaining = append(remaining, described+")")
+	}
+	return message + ". Remaining: " + strings.Join(remaining, "; ")
+}
@@ -3,12 +3,19 @@
 package namespace
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path"
+	"sort"
 	"strings"
+	"text/template"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -16,6 +23,60 @@ func GenerateNamespaceName(crdName string, prefix string) string {
 	return prefix + crdName
 }
 
+// Length of the hash appended to truncated namespace names
+const hashSuffixLength = 8
+
+// Data a namespace name template is rendered with
+type NameTemplateData struct {
+	// Name of the CRD
+	Name string
+	// Namespace of the CRD
+	Namespace string
+	// Prefix set in the CRD
+	Prefix string
+	// Labels of the CRD
+	Labels map[string]string
+	// Spec of the CRD
+	Spec interface{}
+	// Env is the name of the environment being rendered, if any
+	Env string
+}
+
+// Renders a Go text/template into a namespace name. Fails on unknown keys.
+// The result still has to go through ValidateNamespaceName.
+func RenderNamespaceName(nameTemplate string, data NameTemplateData) (string, error) {
+	tmpl, err := template.New("nameTemplate").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("could not parse name template %q: %w", nameTemplate, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("could not render name template %q: %w", nameTemplate, err)
+	}
+	return rendered.String(), nil
+}
+
+// Checks a namespace name against the DNS-1123 label rules
+func ValidateNamespaceName(name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("%q is not a valid namespace name: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Shortens a name to the namespace length limit. The end of the name is
+// replaced by a hash of the full name so different long names stay distinct
+// and the same name always truncates the same way.
+func TruncateNamespaceName(name string) string {
+	if len(name) <= validation.DNS1123LabelMaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:hashSuffixLength]
+	base := strings.TrimRight(name[:validation.DNS1123LabelMaxLength-hashSuffixLength-1], "-.")
+	return base + "-" + suffix
+}
+
 func DeleteNamespace(ctx context.Context, k8sClient client.Client, name string) error {
 	ns := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -58,30 +119,82 @@ func MapToStrings(m map[string]string) string {
 	return strings.Join(result, ", ")
 }
 
-// Compare maps and give priority to values in map1 (CRD) than in map2 (NS).
-// Keeps labels present in map2 but not in map1.
-func MergeMaps(map1 map[string]string, map2 map[string]string) map[string]string {
-	diff := make(map[string]string)
-	for key1, value1 := range map1 {
-		diff[key1] = value1
-	}
-	for key2, value2 := range map2 {
-		if _, exists := diff[key2]; !exists &&
-			strings.Contains(key2, "kubernetes.io") {
-			diff[key2] = value2
+// Enforces the desired labels over the live ones. Live labels not desired
+// are dropped, unless they are kubernetes.io ones or listed in kept.
+func MergeLabels(desired map[string]string, live map[string]string, kept []string) map[string]string {
+	merged := make(map[string]string)
+	for key, value := range desired {
+		merged[key] = value
+	}
+	for key, value := range live {
+		if _, exists := merged[key]; exists {
+			continue
+		}
+		if strings.Contains(key, "kubernetes.io") || ContainsString(kept, key) {
+			merged[key] = value
 		}
 	}
-	return diff
+	return merged
 }
 
-// Takes ns name and strips a prefix if there is any
-func DeriveNamespaceConfigNameFromNamespace(nsName string) string {
-	divided := strings.Split(nsName, "-")
-	if len(divided) > 1 {
-		noPrefixName := strings.Join(divided[1:], "")
-		return noPrefixName
-	} else {
-		return nsName
+// Overlays the desired annotations on the live ones. Keys listed in
+// previouslySet but no longer desired are dropped, every other live key is kept.
+func MergeAnnotations(desired map[string]string, live map[string]string, previouslySet []string) map[string]string {
+	merged := make(map[string]string)
+	for key, value := range live {
+		merged[key] = value
 	}
+	for _, key := range previouslySet {
+		if _, exists := desired[key]; !exists {
+			delete(merged, key)
+		}
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Returns the sorted keys of a map
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
+// Checks if a namespace name matches one of the patterns. Patterns are exact
+// names or globs such as kube-*.
+func MatchesPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses a list of namespace patterns, one per line or separated by commas.
+// Blank lines and lines starting with # are skipped.
+func ParsePatterns(data string) ([]string, error) {
+	var patterns []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, pattern := range strings.Split(line, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+			}
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, nil
 }
@@ -0,0 +1,232 @@
+package namespace
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestTruncateNamespaceName(t *testing.T) {
+	long := strings.Repeat("a", 70)
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "short name", input: "team-a"},
+		{name: "name at the limit", input: strings.Repeat("a", validation.DNS1123LabelMaxLength)},
+		{name: "long name", input: long},
+		{name: "long name cut on a dash", input: strings.Repeat("a", 53) + "-" + strings.Repeat("b", 20)},
+		{name: "long name cut on dashes and dots", input: strings.Repeat("a", 50) + "-.-." + strings.Repeat("b", 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateNamespaceName(tt.input)
+			if len(tt.input) <= validation.DNS1123LabelMaxLength {
+				if got != tt.input {
+					t.Fatalf("TruncateNamespaceName(%q) = %q, want it unchanged", tt.input, got)
+				}
+				return
+			}
+			if len(got) > validation.DNS1123LabelMaxLength {
+				t.Fatalf("TruncateNamespaceName(%q) = %q, longer than %d", tt.input, got, validation.DNS1123LabelMaxLength)
+			}
+			if err := ValidateNamespaceName(got); err != nil {
+				t.Fatalf("TruncateNamespaceName(%q) = %q, not a valid name: %v", tt.input, got, err)
+			}
+			base := got[:len(got)-hashSuffixLength-1]
+			if strings.HasSuffix(base, "-") || strings.HasSuffix(base, ".") {
+				t.Fatalf("TruncateNamespaceName(%q) = %q, want the cut end trimmed", tt.input, got)
+			}
+			if again := TruncateNamespaceName(tt.input); again != got {
+				t.Fatalf("TruncateNamespaceName(%q) is not stable: %q then %q", tt.input, got, again)
+			}
+		})
+	}
+	if TruncateNamespaceName(long) == TruncateNamespaceName(long+"b") {
+		t.Fatal("different long names truncate to the same name")
+	}
+}
+
+func TestValidateNamespaceName(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: "team-a"},
+		{input: "a1"},
+		{input: "", wantErr: true},
+		{input: "Team-A", wantErr: true},
+		{input: "-team", wantErr: true},
+		{input: "team-", wantErr: true},
+		{input: "team.a", wantErr: true},
+		{input: strings.Repeat("a", 64), wantErr: true},
+	}
+	for _, tt := range tests {
+		err := ValidateNamespaceName(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateNamespaceName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired map[string]string
+		live    map[string]string
+		kept    []string
+		want    map[string]string
+	}{
+		{
+			name:    "undeclared labels are dropped",
+			desired: map[string]string{"team": "a"},
+			live:    map[string]string{"team": "b", "manual": "x"},
+			want:    map[string]string{"team": "a"},
+		},
+		{
+			name:    "kubernetes.io labels are kept",
+			desired: map[string]string{"team": "a"},
+			live:    map[string]string{"kubernetes.io/metadata.name": "ns"},
+			want:    map[string]string{"team": "a", "kubernetes.io/metadata.name": "ns"},
+		},
+		{
+			name:    "kept labels survive unless declared",
+			desired: map[string]string{"team": "a"},
+			live:    map[string]string{"team": "legacy", "cost": "1", "manual": "x"},
+			kept:    []string{"team", "cost"},
+			want:    map[string]string{"team": "a", "cost": "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeLabels(tt.desired, tt.live, tt.kept)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("MergeLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeAnnotations(t *testing.T) {
+	tests := []struct {
+		name          string
+		desired       map[string]string
+		live          map[string]string
+		previouslySet []string
+		want          map[string]string
+	}{
+		{
+			name:    "desired overrides live",
+			desired: map[string]string{"a": "new"},
+			live:    map[string]string{"a": "old"},
+			want:    map[string]string{"a": "new"},
+		},
+		{
+			name:    "foreign keys are kept",
+			desired: map[string]string{"a": "1"},
+			live:    map[string]string{"other": "x"},
+			want:    map[string]string{"a": "1", "other": "x"},
+		},
+		{
+			name:          "keys no longer desired are dropped",
+			desired:       map[string]string{"a": "1"},
+			live:          map[string]string{"a": "1", "b": "2", "other": "x"},
+			previouslySet: []string{"a", "b"},
+			want:          map[string]string{"a": "1", "other": "x"},
+		},
+		{
+			name:          "nothing desired",
+			live:          map[string]string{"a": "1"},
+			previouslySet: []string{"a", ""},
+			want:          map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeAnnotations(tt.desired, tt.live, tt.previouslySet)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("MergeAnnotations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNamespaceName(t *testing.T) {
+	data := NameTemplateData{
+		Name:      "payments",
+		Namespace: "teams",
+		Prefix:    "ric-",
+		Labels:    map[string]string{"tier": "backend"},
+		Env:       "dev",
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "fields", template: "{{.Prefix}}{{.Name}}-{{.Env}}", want: "ric-payments-dev"},
+		{name: "labels", template: "{{.Namespace}}-{{.Labels.tier}}", want: "teams-backend"},
+		{name: "missing label", template: "{{.Labels.missing}}", wantErr: true},
+		{name: "unknown field", template: "{{.Unknown}}", wantErr: true},
+		{name: "parse error", template: "{{.Name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderNamespaceName(tt.template, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderNamespaceName(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("RenderNamespaceName(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "comma separated", data: "default, kube-*", want: []string{"default", "kube-*"}},
+		{name: "file", data: "# system\ndefault\n\nkube-*,istio-system\n", want: []string{"default", "kube-*", "istio-system"}},
+		{name: "empty", data: ""},
+		{name: "invalid pattern", data: "kube-[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePatterns(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePatterns(%q) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParsePatterns(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesPattern(t *testing.T) {
+	patterns := []string{"default", "kube-*"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "default", want: true},
+		{name: "kube-system", want: true},
+		{name: "kube-", want: true},
+		{name: "kube", want: false},
+		{name: "default-team", want: false},
+		{name: "team-a", want: false},
+	}
+	for _, tt := range tests {
+		if got := MatchesPattern(tt.name, patterns); got != tt.want {
+			t.Errorf("MatchesPattern(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}